@@ -0,0 +1,260 @@
+// This is the migration tool entry point.
+// It applies, rolls back and inspects the migrations
+// embedded in internal/db/migrations without needing
+// the external migrate binary.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/Oloruntobi1/grey/internal/config"
+	"github.com/Oloruntobi1/grey/internal/db/migrations"
+	"github.com/Oloruntobi1/grey/pkg/logger"
+	"github.com/Oloruntobi1/grey/pkg/redact"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const usage = `usage: migrate [-dry-run] <command> [arg]
+
+commands:
+  up          apply all pending migrations and audit log tables
+  down N      roll back the last N migrations (default 1)
+  goto V      migrate up or down to version V
+  version     print the current version and dirty state
+  force V     set the version to V without running migrations
+  status      list every migration and whether it is applied
+
+flags:
+`
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "print the migrations that would run without applying them")
+	configFile := flag.String("config", "", "optional YAML config file, CONFIG_FILE when empty")
+	var envFiles []string
+	flag.Func("env-file", "env file to load, may be repeated (ENV_FILE, comma separated)", func(file string) error {
+		envFiles = append(envFiles, file)
+		return nil
+	})
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(config.LoadOptions{
+		ConfigFile: *configFile,
+		EnvFiles:   envFiles,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	dbURL := cfg.Database.URL()
+
+	m, err := migrations.NewMigrate(dbURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer m.Close()
+
+	all, err := migrations.ListMigrations()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	current, dirty, err := currentVersion(m)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cmd, arg := flag.Arg(0), flag.Arg(1)
+	switch cmd {
+	case "up":
+		pending := between(all, current, ^uint(0))
+		if *dryRun {
+			printPlan("up", pending)
+			return
+		}
+		ctx := context.Background()
+		pool, err := pgxpool.New(ctx, dbURL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer pool.Close()
+		policy, err := cfg.Redact.Policy()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := migrations.RunDBMigration(ctx, pool, newLogger(cfg.Log, policy), cfg.Database.MigrationLockTimeout, policy); err != nil {
+			log.Fatal(err)
+		}
+
+	case "down":
+		n := 1
+		if arg != "" {
+			n, err = strconv.Atoi(arg)
+			if err != nil || n < 1 {
+				log.Fatalf("down expects a positive number of steps, got %q", arg)
+			}
+		}
+		applied := between(all, 0, current)
+		if n > len(applied) {
+			n = len(applied)
+		}
+		if *dryRun {
+			printPlan("down", reversed(applied[len(applied)-n:]))
+			return
+		}
+		if err := m.Steps(-n); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			log.Fatal(err)
+		}
+
+	case "goto":
+		target, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			log.Fatalf("goto expects a version, got %q", arg)
+		}
+		if *dryRun {
+			if uint(target) >= current {
+				printPlan("up", between(all, current, uint(target)))
+			} else {
+				printPlan("down", reversed(between(all, uint(target), current)))
+			}
+			return
+		}
+		if err := m.Migrate(uint(target)); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			log.Fatal(err)
+		}
+		// tables created on the way up need their audit triggers like after up
+		if uint(target) > current {
+			if err := createAuditLogTables(cfg, dbURL); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+	case "version":
+		fmt.Printf("version: %d dirty: %t\n", current, dirty)
+		return
+
+	case "force":
+		// -1 is accepted to mark the database as having no version.
+		target, err := strconv.Atoi(arg)
+		if err != nil || target < -1 {
+			log.Fatalf("force expects a version, got %q", arg)
+		}
+		if *dryRun {
+			fmt.Printf("would force version %d (currently %d dirty: %t)\n", target, current, dirty)
+			return
+		}
+		if err := m.Force(target); err != nil {
+			log.Fatal(err)
+		}
+
+	case "status":
+		for _, mg := range all {
+			state := "pending"
+			if mg.Version <= current {
+				state = "applied"
+			}
+			if mg.Version == current && dirty {
+				state = "dirty"
+			}
+			fmt.Printf("%d\t%-8s\t%s\n", mg.Version, state, mg.Name)
+		}
+		return
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	current, dirty, err = currentVersion(m)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("version: %d dirty: %t\n", current, dirty)
+}
+
+// currentVersion returns 0 when no migration has been applied yet.
+func currentVersion(m *migrate.Migrate) (uint, bool, error) {
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// between returns the migrations with from < version <= to.
+func between(all []migrations.Migration, from, to uint) []migrations.Migration {
+	var out []migrations.Migration
+	for _, mg := range all {
+		if mg.Version > from && mg.Version <= to {
+			out = append(out, mg)
+		}
+	}
+	return out
+}
+
+func reversed(in []migrations.Migration) []migrations.Migration {
+	out := make([]migrations.Migration, len(in))
+	for i, mg := range in {
+		out[len(in)-1-i] = mg
+	}
+	return out
+}
+
+func printPlan(direction string, plan []migrations.Migration) {
+	if len(plan) == 0 {
+		fmt.Println("no change")
+		return
+	}
+	for _, mg := range plan {
+		fmt.Printf("would run %s\t%d\t%s\n", direction, mg.Version, mg.Name)
+	}
+}
+
+// createAuditLogTables adds the audit tables and triggers
+// of the tables migrate created.
+func createAuditLogTables(cfg *config.Config, dbURL string) error {
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	policy, err := cfg.Redact.Policy()
+	if err != nil {
+		return err
+	}
+	return migrations.CreateAuditLogTables(ctx, pool, policy)
+}
+
+// newLogger logs to the configured output only,
+// the tool does not export telemetry.
+func newLogger(cfg config.LogConfig, policy *redact.Policy) *slog.Logger {
+	output, err := logger.Output(cfg.Output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	level := new(slog.LevelVar)
+	level.Set(cfg.SlogLevel())
+	return logger.New(logger.Options{
+		Level:     level,
+		Format:    cfg.Format,
+		Output:    output,
+		AddSource: cfg.AddSource,
+		Redact:    policy,
+	})
+}
@@ -6,79 +6,112 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
-	"os"
+	"time"
 
+	"github.com/Oloruntobi1/grey/internal/certs"
 	"github.com/Oloruntobi1/grey/internal/config"
 	"github.com/Oloruntobi1/grey/internal/db/migrations"
+	"github.com/Oloruntobi1/grey/internal/db/pool"
+	"github.com/Oloruntobi1/grey/internal/db/replica"
 	db "github.com/Oloruntobi1/grey/internal/db/sqlc"
+	"github.com/Oloruntobi1/grey/internal/db/tracing"
+	"github.com/Oloruntobi1/grey/internal/ratelimit"
 	"github.com/Oloruntobi1/grey/internal/repositories"
+	grpchandlers "github.com/Oloruntobi1/grey/internal/transport/grpc/handlers"
 	"github.com/Oloruntobi1/grey/internal/transport/http/domains/users"
 	"github.com/Oloruntobi1/grey/internal/transport/http/domains/wallets"
+	"github.com/Oloruntobi1/grey/internal/transport/http/gateway"
 	"github.com/Oloruntobi1/grey/internal/transport/http/handlers"
+	"github.com/Oloruntobi1/grey/internal/transport/http/middleware"
 	"github.com/Oloruntobi1/grey/pkg/logger"
 	"github.com/Oloruntobi1/grey/pkg/metrics"
+	"github.com/Oloruntobi1/grey/pkg/redact"
+	"github.com/Oloruntobi1/grey/pkg/telemetry"
 	"github.com/Oloruntobi1/grey/pkg/tracer"
-	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/joho/godotenv"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/codes"
-	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/sdk/resource"
 )
 
-func getAppEnv() (string, string, error) {
-	env := os.Getenv("MY_ENV")
-	var filename string
-
-	switch env {
-	case "":
-		filename = ".env"
-	case "development":
-		filename = "dev.env"
-	case "test":
-		filename = "test.env"
-	case "production":
-		filename = "prod.env"
-	default:
-		return "", "", fmt.Errorf("invalid environment: %v", env)
-	}
-
-	return env, filename, nil
-}
-
 func main() {
 	// We start with making sure the environment is correct.
 	// Prevent app from starting if an error is encountered
 	// in this process.
-	env, envFile, err := getAppEnv()
+	// Env files are only loaded when named explicitly.
+	// Flags override every other source of configuration.
+	configFile := flag.String("config", "", "optional YAML config file, CONFIG_FILE when empty")
+	var envFiles []string
+	flag.Func("env-file", "env file to load, may be repeated (ENV_FILE, comma separated)", func(file string) error {
+		envFiles = append(envFiles, file)
+		return nil
+	})
+	printConfig := flag.Bool("print-config", false, "print the configuration with secrets redacted and exit")
+	// Migrations can be disabled when they are
+	// applied separately with cmd/migrate.
+	autoMigrate := flag.Bool("auto-migrate", true, "run pending migrations on start (DB_AUTO_MIGRATE)")
+	waitForSchema := flag.Bool("wait-for-schema", false, "refuse to serve until the expected schema version is present (DB_WAIT_FOR_SCHEMA)")
+	flag.Parse()
+
+	cfg, err := config.Load(config.LoadOptions{
+		ConfigFile: *configFile,
+		EnvFiles:   envFiles,
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
-
-	if env == "" {
-		env = "local"
-	}
-
-	// Then we load the appropriate env file based on the environment
-	// where the application is running.
-	// Prevent app from starting if an error is also
-	// encountered in this process.
-	err = godotenv.Load(envFile)
-	if err != nil {
-		log.Fatalf("Error loading %s file for %s environment", envFile, env)
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "auto-migrate":
+			cfg.Database.AutoMigrate = *autoMigrate
+		case "wait-for-schema":
+			cfg.Database.WaitForSchema = *waitForSchema
+		}
+	})
+	if *printConfig {
+		fmt.Print(cfg)
+		return
 	}
 
 	// We start a context that will be used throughout the application
 	ctx := context.Background()
 
+	// Personal data is redacted the same way in logs,
+	// spans and audit records.
+	redactPolicy, err := cfg.Redact.Policy()
+	if err != nil {
+		log.Fatal(err)
+	}
+	redact.SetDefault(redactPolicy)
+
 	// After that we initialize our traces and metrics
 	// if the project will be utilizing it
-	serviceName := "grey-wallet-application"
-	tp, err := tracer.StartTracer(ctx, serviceName)
+	res, err := telemetry.NewResource(ctx, cfg.Telemetry.ServiceName, cfg.Telemetry.ServiceVersion, cfg.Telemetry.Environment)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var collectorTLS *tls.Config
+	if !cfg.Telemetry.CollectorInsecure {
+		collectorTLS, err = telemetry.ExporterTLS(cfg.Telemetry.CollectorCAFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	tp, err := tracer.StartTracer(ctx, tracer.Options{
+		Resource:    res,
+		Exporter:    cfg.Telemetry.TracesExporter,
+		Endpoint:    cfg.Telemetry.CollectorEndpoint,
+		TLS:         collectorTLS,
+		Headers:     cfg.Telemetry.CollectorHeaders,
+		SampleRatio: cfg.Telemetry.TracesSampleRatio,
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -88,96 +121,325 @@ func main() {
 		}
 	}()
 
-	mp, err := metrics.SetupMetrics(ctx, serviceName)
+	mp, metricsHandler, err := metrics.SetupMetrics(ctx, metrics.Options{
+		Resource:          res,
+		OTLP:              cfg.Telemetry.MetricsExporter("otlp"),
+		CollectorEndpoint: cfg.Telemetry.CollectorEndpoint,
+		Interval:          cfg.Telemetry.MetricsInterval,
+		TLS:               collectorTLS,
+		Headers:           cfg.Telemetry.CollectorHeaders,
+		Prometheus:        cfg.Telemetry.MetricsExporter("prometheus"),
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	defer func() {
 		if err := mp.Shutdown(ctx); err != nil {
-			log.Printf("Error shutting down tracer provider: %v", err)
+			log.Printf("Error shutting down meter provider: %v", err)
+		}
+	}()
+
+	// Logs are written to the configured output and, like traces
+	// and metrics, can be exported to the collector.
+	logger, logLevelHandler, shutdownLogs, err := newLogger(ctx, cfg.Log, res, cfg.Telemetry, collectorTLS, redactPolicy)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		if err := shutdownLogs(ctx); err != nil {
+			log.Printf("Error shutting down logger provider: %v", err)
 		}
 	}()
 
 	// Next thing is to connect to a database.
 	// Could be any but in this example we will
 	// be using postgres.
-	dbCfg := config.GetDatabaseConfig()
-
-	if envFile == ".env" {
-		dbCfg = fmt.Sprintf("%s?sslmode=disable", dbCfg)
+	pgxConfig, err := pool.NewConfig(cfg.Database)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	if envFile == "dev.env" {
-		dbCfg = fmt.Sprintf("%s?sslmode=disable", dbCfg)
+	queryTracer, err := tracing.New(tracing.ArgsMode(cfg.Database.TraceArgs), redactPolicy)
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	pgxConfig, err := pgxpool.ParseConfig(dbCfg)
+	pgxConfig.ConnConfig.Tracer = queryTracer
+	connPool, err := pgxpool.NewWithConfig(ctx, pgxConfig)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	pgxConfig.ConnConfig.Tracer = &MyQueryTracer{}
-	connPool, err := pgxpool.NewWithConfig(ctx, pgxConfig)
+	poolMetrics, err := pool.RegisterMetrics(connPool, pool.RolePrimary)
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer func() {
+		if err := poolMetrics.Unregister(); err != nil {
+			log.Printf("Error unregistering pool metrics: %v", err)
+		}
+	}()
 
 	// Now that we have obtained our connection pool
 	// we can run our migrations if applicable
+	// When several replicas start together only one of them migrates,
+	// the others wait for it and verify the resulting schema version.
+	if cfg.Database.AutoMigrate {
+		err = migrations.RunDBMigration(ctx, connPool, logger, cfg.Database.MigrationLockTimeout, redactPolicy)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 
-	// But firstly we need to initialize our logger.
-	logger := logger.NewSlog(ctx)
-
-	// Then we will run our migrations
-	err = migrations.RunDBMigration(ctx, connPool, logger)
-	if err != nil {
-		log.Fatal(err)
+	// Replicas that do not migrate can refuse to serve
+	// until the schema they were built against is present.
+	if !cfg.Database.AutoMigrate && cfg.Database.WaitForSchema {
+		err = migrations.WaitForSchemaVersion(ctx, connPool, logger, cfg.Database.SchemaWaitTimeout)
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
 
 	// Obtain all queries
 	dbQueries := db.New(connPool)
 
+	// Reads that can be slightly stale go to the replica when one is set.
+	queryRouter, closeReplica, err := newQueryRouter(ctx, cfg.Database, dbQueries, queryTracer, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeReplica()
+
 	// Use queries to initiliaze repositories
-	userRepository := repositories.NewUserRepository(dbQueries)
-	walletRepository := repositories.NewWalletRepository(dbQueries)
+	userRepository := repositories.NewUserRepository(queryRouter)
+	walletRepository := repositories.NewWalletRepository(queryRouter)
 
-	userService := users.NewUserService(userRepository)
-	walletService := wallets.NewWalletService(walletRepository)
+	userService, err := users.NewUserService(userRepository, cfg.TLS.AdminServices)
+	if err != nil {
+		log.Fatal(err)
+	}
+	walletService, err := wallets.NewWalletService(walletRepository, cfg.TLS.AdminServices)
+	if err != nil {
+		log.Fatal(err)
+	}
+	balanceMetrics, err := walletService.RegisterBalanceMetrics()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		if err := balanceMetrics.Unregister(); err != nil {
+			log.Printf("Error unregistering balance metrics: %v", err)
+		}
+	}()
 
-	userHandler := handlers.NewUserHandler(*userService, logger)
-	walletHandler := handlers.NewWalletHandler(*walletService, logger)
+	userHandler := handlers.NewUserHandler(*userService, logger, cfg.Server.MaxBodyBytes)
+	walletHandler := handlers.NewWalletHandler(*walletService, logger, cfg.Server.MaxBodyBytes)
 
-	// TODO: attach the tracing to middleware
+	// The gRPC servers back both the gRPC transport and the
+	// REST routes generated from the same protobuf contract.
+	userServer := grpchandlers.NewUserServer(*userService, logger)
+	walletServer := grpchandlers.NewWalletServer(*walletService, logger)
+	transferServer := grpchandlers.NewTransferServer()
 
-	router := handlers.SetupRouter(ctx, *userHandler, *walletHandler)
+	gatewayHandler, err := gateway.NewHandler(ctx, userServer, walletServer, transferServer, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rateLimiter, err := newRateLimiter(ctx, cfg.RateLimit, dbQueries, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	router, err := handlers.SetupRouter(ctx, *userHandler, *walletHandler, gatewayHandler, rateLimiter)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Browser clients need CORS, the security headers apply to everyone.
+	if len(cfg.Server.CORS.AllowedOrigins) > 0 {
+		cors, err := middleware.NewCORS(middleware.CORSOptions{
+			AllowedOrigins:   cfg.Server.CORS.AllowedOrigins,
+			AllowedMethods:   cfg.Server.CORS.AllowedMethods,
+			AllowedHeaders:   cfg.Server.CORS.AllowedHeaders,
+			AllowCredentials: cfg.Server.CORS.AllowCredentials,
+			MaxAge:           cfg.Server.CORS.MaxAge,
+		}, logger)
+		if err != nil {
+			log.Fatal(err)
+		}
+		router = cors.Handler(router)
+	}
+	router = middleware.SecurityHeaders(middleware.SecurityHeadersOptions{
+		HSTSMaxAge:            cfg.Server.HSTS.MaxAge,
+		HSTSIncludeSubdomains: cfg.Server.HSTS.IncludeSubdomains,
+		PathCSP:               map[string]string{"/docs": middleware.DocsCSP},
+	}, router)
+
+	// Every request gets a server span, so panics, logs and
+	// error envelopes carry the trace ID of the request.
+	recoverer, err := middleware.NewRecoverer(logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	router = otelhttp.NewHandler(recoverer.Handler(middleware.ReadYourWrites(router)), "http-server")
 
-	log.Fatal(http.ListenAndServe(":9191", router))
+	// The gRPC transport serves the same services on its own port.
+	grpcServer := grpchandlers.NewServer(userServer, walletServer, transferServer, logger)
+	grpcListener, err := net.Listen("tcp", ":"+cfg.Server.GRPCPort)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		log.Fatal(grpcServer.Serve(grpcListener))
+	}()
+
+	// Operational endpoints are kept off the public port.
+	if cfg.Server.AdminAddr != "" {
+		adminMux := http.NewServeMux()
+		if metricsHandler != nil {
+			adminMux.Handle("GET /metrics", metricsHandler)
+		}
+		adminMux.Handle("/log/level", logLevelHandler)
+		go func() {
+			log.Fatal(http.ListenAndServe(cfg.Server.AdminAddr, adminMux))
+		}()
+	}
+
+	log.Fatal(serveHTTP(ctx, cfg.Server.Addr, cfg.TLS, router, logger))
 }
 
-type MyQueryTracer struct{}
+// newLogger builds the logger from its settings. The returned handler
+// changes the level at runtime and shutdown flushes exported logs.
+func newLogger(ctx context.Context, cfg config.LogConfig, res *resource.Resource, telemetryCfg config.TelemetryConfig, collectorTLS *tls.Config, policy *redact.Policy) (*slog.Logger, http.Handler, func(context.Context) error, error) {
+	output, err := logger.Output(cfg.Output)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
-func (t *MyQueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, d pgx.TraceQueryStartData) context.Context {
-	ctx, span := otel.Tracer("").Start(ctx, "execute_query")
-	defer span.End()
+	level := new(slog.LevelVar)
+	level.Set(cfg.SlogLevel())
+	opts := logger.Options{
+		Level:     level,
+		Format:    cfg.Format,
+		Output:    output,
+		AddSource: cfg.AddSource,
+		Redact:    policy,
+	}
 
-	span.SetAttributes(attribute.String("sql", d.SQL))
-	argStrings := make([]string, len(d.Args))
-	for i, arg := range d.Args {
-		argStrings[i] = fmt.Sprintf("%v", arg) // Use fmt.Sprintf to handle any type
+	shutdown := func(context.Context) error { return nil }
+	if cfg.OTLP {
+		provider, err := logger.NewOTLPProvider(ctx, logger.OTLPOptions{
+			Resource:          res,
+			CollectorEndpoint: telemetryCfg.CollectorEndpoint,
+			TLS:               collectorTLS,
+			Headers:           telemetryCfg.CollectorHeaders,
+		})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		opts.Provider = provider
+		shutdown = provider.Shutdown
 	}
-	span.SetAttributes(attribute.StringSlice("db.statement.parameters", argStrings))
 
-	return trace.ContextWithSpan(ctx, span)
+	l := logger.New(opts)
+	return l, logger.LevelHandler(level, l), shutdown, nil
 }
 
-func (t *MyQueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, d pgx.TraceQueryEndData) {
-	span := trace.SpanFromContext(ctx) // Assuming you're using OpenTelemetry
-	if d.Err != nil {
-		span.SetStatus(codes.Error, "database error")
-		span.RecordError(d.Err)
-	} else {
-		span.AddEvent("successfully executed SQL statement")
+// serveHTTP serves plaintext HTTP unless TLS is configured. With a
+// client CA, callers presenting a verified certificate are
+// authenticated as the service principal named by it.
+func serveHTTP(ctx context.Context, addr string, tlsCfg config.TLSConfig, handler http.Handler, logger *slog.Logger) error {
+	if !tlsCfg.Enabled() {
+		return http.ListenAndServe(addr, handler)
+	}
+
+	clientAuth, err := certs.ParseClientAuth(tlsCfg.ClientAuth)
+	if err != nil {
+		return err
+	}
+
+	reloader, err := certs.NewReloader(tlsCfg.CertFile, tlsCfg.KeyFile, tlsCfg.ClientCAFile, logger)
+	if err != nil {
+		return err
+	}
+	go reloader.Run(ctx, tlsCfg.ReloadInterval)
+
+	server := &http.Server{
+		Addr:      addr,
+		Handler:   middleware.ClientCertPrincipal(tlsCfg.ClientPrincipals, handler),
+		TLSConfig: reloader.TLSConfig(clientAuth),
+	}
+	// the certificates come from TLSConfig
+	return server.ListenAndServeTLS("", "")
+}
+
+// newQueryRouter connects to the replica when one is configured and
+// keeps measuring its lag in the background. The returned func stops
+// reporting the replica pool metrics and closes the pool.
+func newQueryRouter(ctx context.Context, cfg config.DatabaseConfig, primary *db.Queries, queryTracer *tracing.Tracer, logger *slog.Logger) (*replica.Router, func(), error) {
+	if !cfg.Replica.Enabled() {
+		return replica.NewRouter(primary, nil, 0, logger), func() {}, nil
+	}
+
+	pgxConfig, err := pool.NewConfig(cfg.ReplicaDatabase())
+	if err != nil {
+		return nil, nil, err
+	}
+	pgxConfig.ConnConfig.Tracer = queryTracer
+	replicaPool, err := pgxpool.NewWithConfig(ctx, pgxConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	replicaMetrics, err := pool.RegisterMetrics(replicaPool, pool.RoleReplica)
+	if err != nil {
+		replicaPool.Close()
+		return nil, nil, err
+	}
+	closeReplica := func() {
+		if err := replicaMetrics.Unregister(); err != nil {
+			log.Printf("Error unregistering replica pool metrics: %v", err)
+		}
+		replicaPool.Close()
+	}
+
+	router := replica.NewRouter(primary, db.New(replicaPool), cfg.Replica.MaxLag, logger)
+	go router.Run(ctx, cfg.Replica.LagCheckInterval)
+	return router, closeReplica, nil
+}
+
+// newRateLimiter builds the rate limiter from the validated settings,
+// it returns nil when rate limiting is disabled.
+func newRateLimiter(ctx context.Context, cfg config.RateLimitConfig, queries *db.Queries, logger *slog.Logger) (*middleware.RateLimiter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	defaultLimit, err := ratelimit.ParseLimit(cfg.Default)
+	if err != nil {
+		return nil, err
+	}
+	routeLimits, err := ratelimit.ParseLimits(cfg.Routes)
+	if err != nil {
+		return nil, err
+	}
+	principalLimits, err := ratelimit.ParseLimits(cfg.Principals)
+	if err != nil {
+		return nil, err
+	}
+
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.Store == "postgres" {
+		store = ratelimit.NewPostgresStore(queries)
 	}
-	span.End()
+	go ratelimit.RunSweeper(ctx, store, time.Minute, logger)
+
+	return middleware.NewRateLimiter(
+		store,
+		logger,
+		defaultLimit,
+		routeLimits,
+		principalLimits,
+		cfg.TrustProxy,
+	), nil
 }
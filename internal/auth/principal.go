@@ -0,0 +1,67 @@
+// Package auth carries the authenticated caller of a request
+// from the transport to the authorization checks.
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrUnauthenticated  = errors.New("the caller is not authenticated")
+	ErrPermissionDenied = errors.New("the caller may not do this")
+)
+
+type PrincipalKind string
+
+// PrincipalService is a service authenticated by its client certificate.
+const PrincipalService PrincipalKind = "service"
+
+// Principal is an authenticated caller.
+type Principal struct {
+	Kind PrincipalKind
+	Name string
+}
+
+func (p Principal) String() string {
+	return string(p.Kind) + ":" + p.Name
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the authenticated caller of the request,
+// false when the request is anonymous.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// IsService reports whether ctx belongs to one of the named services.
+func IsService(ctx context.Context, names ...string) bool {
+	p, ok := PrincipalFrom(ctx)
+	if !ok || p.Kind != PrincipalService {
+		return false
+	}
+	for _, name := range names {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireService returns ErrUnauthenticated for anonymous callers and
+// ErrPermissionDenied for ones that are not one of the named services.
+func RequireService(ctx context.Context, names ...string) error {
+	if _, ok := PrincipalFrom(ctx); !ok {
+		return ErrUnauthenticated
+	}
+	if !IsService(ctx, names...) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
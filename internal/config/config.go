@@ -0,0 +1,158 @@
+// Package config loads the application settings into a typed Config.
+//
+// Values are layered, each source overriding the previous one:
+// the defaults, an optional YAML file and finally the environment,
+// optionally filled from env files. Secrets can be read from files
+// named by the <key>_FILE variants of their variables.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	// EnvFiles are the env files that were loaded,
+	// they cannot be set in YAML.
+	EnvFiles []string `yaml:"-"`
+
+	Server    ServerConfig    `yaml:"server"`
+	TLS       TLSConfig       `yaml:"tls"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Telemetry TelemetryConfig `yaml:"telemetry"`
+	Log       LogConfig       `yaml:"log"`
+	Redact    RedactConfig    `yaml:"redact"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+}
+
+// Default returns the settings used when nothing overrides them.
+func Default() Config {
+	return Config{
+		Server:    defaultServerConfig(),
+		TLS:       defaultTLSConfig(),
+		Database:  defaultDatabaseConfig(),
+		Telemetry: defaultTelemetryConfig(),
+		Log:       defaultLogConfig(),
+		Redact:    defaultRedactConfig(),
+		RateLimit: defaultRateLimitConfig(),
+	}
+}
+
+// LoadOptions tells Load where to find the settings.
+type LoadOptions struct {
+	// ConfigFile is the YAML file, CONFIG_FILE when empty
+	// and none when both are.
+	ConfigFile string
+	// EnvFiles are loaded into the environment without overriding
+	// it, ENV_FILE (comma separated) when empty.
+	EnvFiles []string
+	// Secrets resolves the <key>_FILE variables of secret
+	// settings, FileSecrets when nil.
+	Secrets SecretSource
+}
+
+// Load builds the Config from the defaults, the YAML file, the env
+// files and the environment. Every invalid setting is reported in
+// the error.
+func Load(opts LoadOptions) (*Config, error) {
+	if opts.ConfigFile == "" {
+		opts.ConfigFile = os.Getenv("CONFIG_FILE")
+	}
+	if len(opts.EnvFiles) == 0 && os.Getenv("ENV_FILE") != "" {
+		opts.EnvFiles = strings.Split(os.Getenv("ENV_FILE"), ",")
+	}
+	if opts.Secrets == nil {
+		opts.Secrets = FileSecrets{}
+	}
+
+	if err := loadEnvFiles(opts.EnvFiles); err != nil {
+		return nil, err
+	}
+
+	c := Default()
+	c.EnvFiles = opts.EnvFiles
+
+	if opts.ConfigFile != "" {
+		if err := c.loadYAML(opts.ConfigFile); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &envReader{secrets: opts.Secrets}
+	c.Server.readEnv(r)
+	c.TLS.readEnv(r)
+	c.Database.readEnv(r)
+	c.Telemetry.readEnv(r)
+	c.Log.readEnv(r)
+	c.Redact.readEnv(r)
+	c.RateLimit.readEnv(r)
+
+	if err := errors.Join(append(r.errs, c.validate()...)...); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	return &c, nil
+}
+
+func (c *Config) loadYAML(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// unknown keys are most likely typos, refuse them
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c Config) validate() []error {
+	var errs []error
+	errs = append(errs, c.Server.validate()...)
+	errs = append(errs, c.TLS.validate()...)
+	errs = append(errs, c.Database.validate()...)
+	errs = append(errs, c.Telemetry.validate()...)
+	errs = append(errs, c.Log.validate()...)
+	errs = append(errs, c.Redact.validate()...)
+	errs = append(errs, c.RateLimit.validate()...)
+	if c.Telemetry.MetricsExporter("prometheus") && c.Server.AdminAddr == "" {
+		errs = append(errs, invalid("METRICS_EXPORTERS", "prometheus requires ADMIN_ADDR"))
+	}
+	return errs
+}
+
+// redacted replaces secrets so they do not leak into logs.
+const redacted = "REDACTED"
+
+// Redacted returns a copy of c that is safe to print.
+func (c Config) Redacted() Config {
+	c.Database = c.Database.redacted()
+	c.Telemetry = c.Telemetry.redacted()
+	c.Redact = c.Redact.redacted()
+	c.RateLimit = c.RateLimit.redacted()
+	return c
+}
+
+// String prints the redacted config as YAML.
+func (c Config) String() string {
+	b, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return fmt.Sprintf("unprintable config: %v", err)
+	}
+	if len(c.EnvFiles) == 0 {
+		return string(b)
+	}
+	return fmt.Sprintf("# env files: %s\n%s", strings.Join(c.EnvFiles, ", "), b)
+}
+
+func invalid(key, format string, args ...interface{}) error {
+	return fmt.Errorf("%s: "+format, append([]interface{}{key}, args...)...)
+}
@@ -1,15 +1,247 @@
 package config
 
 import (
-	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"time"
 )
 
-func GetDatabaseConfig() string {
-	user := getEnv("POSTGRES_USER", "db_user")
-	password := getEnv("POSTGRES_PASSWORD", "db_pass")
-	host := getEnv("POSTGRES_HOST", "localhost")
-	port := getEnv("POSTGRES_PORT", "5444")
-	name := getEnv("POSTGRES_DB_NAME", "grey-app-db")
-	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s",
-		user, password, host, port, name)
+type DatabaseConfig struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Name     string `yaml:"name"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	// SSLMode is passed to the driver as sslmode, prefer falls
+	// back to plaintext when the server does not offer TLS.
+	SSLMode string `yaml:"sslmode"`
+	// SSLRootCert is the CA bundle used to verify the server
+	// certificate with sslmode verify-ca or verify-full.
+	SSLRootCert string `yaml:"sslrootcert"`
+	// TargetSessionAttrs picks which of several hosts to connect
+	// to, such as read-write to always land on the primary.
+	TargetSessionAttrs string `yaml:"target_session_attrs"`
+	// ApplicationName shows up in pg_stat_activity and the server logs.
+	ApplicationName string `yaml:"application_name"`
+	// StatementTimeout cancels statements running longer than it,
+	// zero leaves the server setting in place.
+	StatementTimeout time.Duration `yaml:"statement_timeout"`
+	// TraceArgs is how query arguments are recorded on spans:
+	// off, redacted (types only) or raw.
+	TraceArgs string `yaml:"trace_args"`
+
+	Pool PoolConfig `yaml:"pool"`
+	// Replica serves reads that can be slightly stale,
+	// it is off unless a host is set.
+	Replica ReplicaConfig `yaml:"replica"`
+
+	// AutoMigrate runs pending migrations when the server starts.
+	AutoMigrate bool `yaml:"auto_migrate"`
+	// MigrationLockTimeout is how long a replica waits for
+	// another replica to finish migrating before giving up.
+	MigrationLockTimeout time.Duration `yaml:"migration_lock_timeout"`
+	// WaitForSchema makes the server refuse to serve until
+	// the expected schema version has been applied.
+	WaitForSchema bool `yaml:"wait_for_schema"`
+	// SchemaWaitTimeout bounds how long the server waits
+	// for the expected schema version when starting.
+	SchemaWaitTimeout time.Duration `yaml:"schema_wait_timeout"`
+}
+
+// PoolConfig sizes the pgx connection pool, zero values
+// leave the pgxpool defaults in place.
+type PoolConfig struct {
+	MaxConns          int32         `yaml:"max_conns"`
+	MinConns          int32         `yaml:"min_conns"`
+	MaxConnLifetime   time.Duration `yaml:"max_conn_lifetime"`
+	MaxConnIdleTime   time.Duration `yaml:"max_conn_idle_time"`
+	HealthCheckPeriod time.Duration `yaml:"health_check_period"`
+}
+
+// ReplicaConfig points reads at a streaming replica of the primary.
+// It shares the credentials and settings of the primary.
+type ReplicaConfig struct {
+	Host string `yaml:"host"`
+	Port string `yaml:"port"`
+	// MaxLag is how far behind the primary the replica may be
+	// before reads go back to the primary.
+	MaxLag time.Duration `yaml:"max_lag"`
+	// LagCheckInterval is how often the replica lag is measured.
+	LagCheckInterval time.Duration `yaml:"lag_check_interval"`
+}
+
+func (c ReplicaConfig) Enabled() bool {
+	return c.Host != ""
+}
+
+var (
+	sslModes           = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	targetSessionAttrs = []string{"any", "read-write", "read-only", "primary", "standby", "prefer-standby"}
+	traceArgsModes     = []string{"off", "redacted", "raw"}
+)
+
+func defaultDatabaseConfig() DatabaseConfig {
+	return DatabaseConfig{
+		Host:               "localhost",
+		Port:               "5444",
+		Name:               "grey-app-db",
+		SSLMode:            "prefer",
+		TargetSessionAttrs: "any",
+		ApplicationName:    "grey-wallet-application",
+		TraceArgs:          "redacted",
+		Replica: ReplicaConfig{
+			Port:             "5432",
+			MaxLag:           5 * time.Second,
+			LagCheckInterval: time.Second,
+		},
+		AutoMigrate:          true,
+		MigrationLockTimeout: 2 * time.Minute,
+		SchemaWaitTimeout:    5 * time.Minute,
+	}
+}
+
+func (c *DatabaseConfig) readEnv(r *envReader) {
+	r.string("POSTGRES_HOST", &c.Host)
+	r.string("POSTGRES_PORT", &c.Port)
+	r.string("POSTGRES_DB_NAME", &c.Name)
+	r.string("POSTGRES_USER", &c.User)
+	r.string("POSTGRES_PASSWORD", &c.Password)
+	r.string("POSTGRES_SSLMODE", &c.SSLMode)
+	r.string("POSTGRES_SSLROOTCERT", &c.SSLRootCert)
+	r.string("POSTGRES_TARGET_SESSION_ATTRS", &c.TargetSessionAttrs)
+	r.string("POSTGRES_APPLICATION_NAME", &c.ApplicationName)
+	r.duration("DB_STATEMENT_TIMEOUT", &c.StatementTimeout)
+	r.string("DB_TRACE_ARGS", &c.TraceArgs)
+	r.int32("DB_POOL_MAX_CONNS", &c.Pool.MaxConns)
+	r.int32("DB_POOL_MIN_CONNS", &c.Pool.MinConns)
+	r.duration("DB_POOL_MAX_CONN_LIFETIME", &c.Pool.MaxConnLifetime)
+	r.duration("DB_POOL_MAX_CONN_IDLE_TIME", &c.Pool.MaxConnIdleTime)
+	r.duration("DB_POOL_HEALTH_CHECK_PERIOD", &c.Pool.HealthCheckPeriod)
+	r.string("POSTGRES_REPLICA_HOST", &c.Replica.Host)
+	r.string("POSTGRES_REPLICA_PORT", &c.Replica.Port)
+	r.duration("DB_REPLICA_MAX_LAG", &c.Replica.MaxLag)
+	r.duration("DB_REPLICA_LAG_CHECK_INTERVAL", &c.Replica.LagCheckInterval)
+	r.bool("DB_AUTO_MIGRATE", &c.AutoMigrate)
+	r.duration("DB_MIGRATION_LOCK_TIMEOUT", &c.MigrationLockTimeout)
+	r.bool("DB_WAIT_FOR_SCHEMA", &c.WaitForSchema)
+	r.duration("DB_SCHEMA_WAIT_TIMEOUT", &c.SchemaWaitTimeout)
+}
+
+// URL returns the connection string for pgx and golang-migrate.
+// The statement timeout is left out so long running migrations
+// are not cancelled, the pool sets it on its own connections.
+func (c DatabaseConfig) URL() string {
+	query := url.Values{
+		"sslmode":              {c.SSLMode},
+		"target_session_attrs": {c.TargetSessionAttrs},
+	}
+	if c.SSLRootCert != "" {
+		query.Set("sslrootcert", c.SSLRootCert)
+	}
+	if c.ApplicationName != "" {
+		query.Set("application_name", c.ApplicationName)
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     c.Host + ":" + c.Port,
+		Path:     "/" + c.Name,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// ReplicaDatabase returns the settings for connecting to the replica.
+func (c DatabaseConfig) ReplicaDatabase() DatabaseConfig {
+	c.Host = c.Replica.Host
+	c.Port = c.Replica.Port
+	// The replica is in recovery, asking for a
+	// writable session would never connect.
+	c.TargetSessionAttrs = "any"
+	return c
+}
+
+func (c DatabaseConfig) validate() []error {
+	var errs []error
+	for _, f := range []struct{ key, value string }{
+		{"POSTGRES_HOST", c.Host},
+		{"POSTGRES_DB_NAME", c.Name},
+		{"POSTGRES_USER", c.User},
+		{"POSTGRES_PASSWORD", c.Password},
+	} {
+		if f.value == "" {
+			errs = append(errs, invalid(f.key, "must not be empty"))
+		}
+	}
+	if !validPort(c.Port) {
+		errs = append(errs, invalid("POSTGRES_PORT", "%q is not a port number", c.Port))
+	}
+	if !slices.Contains(sslModes, c.SSLMode) {
+		errs = append(errs, invalid("POSTGRES_SSLMODE", "%q is not one of %v", c.SSLMode, sslModes))
+	}
+	if c.SSLRootCert != "" {
+		if _, err := os.Stat(c.SSLRootCert); err != nil {
+			errs = append(errs, invalid("POSTGRES_SSLROOTCERT", "%v", err))
+		}
+	}
+	if !slices.Contains(targetSessionAttrs, c.TargetSessionAttrs) {
+		errs = append(errs, invalid("POSTGRES_TARGET_SESSION_ATTRS", "%q is not one of %v", c.TargetSessionAttrs, targetSessionAttrs))
+	}
+	if !slices.Contains(traceArgsModes, c.TraceArgs) {
+		errs = append(errs, invalid("DB_TRACE_ARGS", "%q is not one of %v", c.TraceArgs, traceArgsModes))
+	}
+	if c.StatementTimeout < 0 {
+		errs = append(errs, invalid("DB_STATEMENT_TIMEOUT", "must not be negative"))
+	}
+
+	if c.Pool.MaxConns < 0 {
+		errs = append(errs, invalid("DB_POOL_MAX_CONNS", "must not be negative"))
+	}
+	if c.Pool.MinConns < 0 {
+		errs = append(errs, invalid("DB_POOL_MIN_CONNS", "must not be negative"))
+	}
+	if c.Pool.MaxConns > 0 && c.Pool.MinConns > c.Pool.MaxConns {
+		errs = append(errs, invalid("DB_POOL_MIN_CONNS", "must not exceed DB_POOL_MAX_CONNS (%d)", c.Pool.MaxConns))
+	}
+	for _, f := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"DB_POOL_MAX_CONN_LIFETIME", c.Pool.MaxConnLifetime},
+		{"DB_POOL_MAX_CONN_IDLE_TIME", c.Pool.MaxConnIdleTime},
+		{"DB_POOL_HEALTH_CHECK_PERIOD", c.Pool.HealthCheckPeriod},
+	} {
+		if f.value < 0 {
+			errs = append(errs, invalid(f.key, "must not be negative"))
+		}
+	}
+
+	if c.Replica.Enabled() {
+		if !validPort(c.Replica.Port) {
+			errs = append(errs, invalid("POSTGRES_REPLICA_PORT", "%q is not a port number", c.Replica.Port))
+		}
+		if c.Replica.MaxLag <= 0 {
+			errs = append(errs, invalid("DB_REPLICA_MAX_LAG", "must be positive"))
+		}
+		if c.Replica.LagCheckInterval <= 0 {
+			errs = append(errs, invalid("DB_REPLICA_LAG_CHECK_INTERVAL", "must be positive"))
+		}
+	}
+
+	if c.MigrationLockTimeout <= 0 {
+		errs = append(errs, invalid("DB_MIGRATION_LOCK_TIMEOUT", "must be positive"))
+	}
+	if c.SchemaWaitTimeout <= 0 {
+		errs = append(errs, invalid("DB_SCHEMA_WAIT_TIMEOUT", "must be positive"))
+	}
+	return errs
+}
+
+func (c DatabaseConfig) redacted() DatabaseConfig {
+	if c.Password != "" {
+		c.Password = redacted
+	}
+	return c
 }
@@ -1,11 +1,174 @@
 package config
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
-func getEnv(k, defaultVal string) string {
+	"github.com/joho/godotenv"
+)
+
+// envReader overrides config fields with the environment variables
+// that are set. Values that do not parse are collected in errs so they
+// are reported together with the validation errors.
+type envReader struct {
+	secrets SecretSource
+	errs    []error
+}
+
+// secretKeys are the settings that can also be read through the
+// secret source from the reference in <key>_FILE, the way Docker
+// and Kubernetes secrets are mounted.
+var secretKeys = map[string]bool{
+	"POSTGRES_USER":          true,
+	"POSTGRES_PASSWORD":      true,
+	"RATE_LIMIT_PRINCIPALS":  true,
+	"OTEL_COLLECTOR_HEADERS": true,
+	"REDACT_HASH_KEY":        true,
+}
+
+func (r *envReader) lookup(k string) (string, bool) {
 	v := os.Getenv(k)
+	if !secretKeys[k] {
+		return v, v != ""
+	}
+
+	ref := os.Getenv(k + "_FILE")
+	if ref == "" {
+		return v, v != ""
+	}
 	if v != "" {
-		return v
+		r.errs = append(r.errs, fmt.Errorf("%s: set either %s or %s_FILE, not both", k, k, k))
+		return "", false
+	}
+	secret, err := r.secrets.Secret(ref)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s_FILE: %w", k, err))
+		return "", false
+	}
+	return secret, secret != ""
+}
+
+func (r *envReader) invalid(k, v, want string) {
+	r.errs = append(r.errs, fmt.Errorf("%s: %q is not %s", k, v, want))
+}
+
+func (r *envReader) string(k string, dst *string) {
+	if v, ok := r.lookup(k); ok {
+		*dst = v
+	}
+}
+
+func (r *envReader) bool(k string, dst *bool) {
+	v, ok := r.lookup(k)
+	if !ok {
+		return
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		r.invalid(k, v, "a boolean")
+		return
+	}
+	*dst = b
+}
+
+func (r *envReader) int32(k string, dst *int32) {
+	v, ok := r.lookup(k)
+	if !ok {
+		return
+	}
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil {
+		r.invalid(k, v, "an integer")
+		return
+	}
+	*dst = int32(n)
+}
+
+func (r *envReader) int64(k string, dst *int64) {
+	v, ok := r.lookup(k)
+	if !ok {
+		return
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		r.invalid(k, v, "an integer")
+		return
+	}
+	*dst = n
+}
+
+func (r *envReader) float64(k string, dst *float64) {
+	v, ok := r.lookup(k)
+	if !ok {
+		return
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		r.invalid(k, v, "a number")
+		return
+	}
+	*dst = f
+}
+
+func (r *envReader) duration(k string, dst *time.Duration) {
+	v, ok := r.lookup(k)
+	if !ok {
+		return
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		r.invalid(k, v, "a duration such as 30s")
+		return
+	}
+	*dst = d
+}
+
+// list splits a comma separated value, dropping empty items.
+func (r *envReader) list(k string, dst *[]string) {
+	v, ok := r.lookup(k)
+	if !ok {
+		return
+	}
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	*dst = list
+}
+
+// stringMap parses key=value pairs separated by semicolons.
+func (r *envReader) stringMap(k string, dst *map[string]string) {
+	v, ok := r.lookup(k)
+	if !ok {
+		return
+	}
+	m := map[string]string{}
+	for _, pair := range strings.Split(v, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			r.invalid(k, pair, "a key=value pair")
+			return
+		}
+		m[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	*dst = m
+}
+
+// loadEnvFiles adds the variables of every file to the environment.
+// Variables already set in the environment are kept.
+func loadEnvFiles(files []string) error {
+	for _, file := range files {
+		if err := godotenv.Load(file); err != nil {
+			return fmt.Errorf("failed to load env file: %w", err)
+		}
 	}
-	return defaultVal
+	return nil
 }
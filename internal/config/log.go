@@ -0,0 +1,64 @@
+package config
+
+import (
+	"log/slog"
+	"slices"
+)
+
+type LogConfig struct {
+	// Level is debug, info, warn or error. It can be
+	// changed at runtime on the admin address.
+	Level string `yaml:"level"`
+	// Format is json or text.
+	Format string `yaml:"format"`
+	// Output is stdout, stderr or the path of a file logs are appended to.
+	Output string `yaml:"output"`
+	// AddSource adds the file and line of the log call.
+	AddSource bool `yaml:"add_source"`
+	// OTLP also sends logs to the collector, correlated with the
+	// trace of the request they were written in.
+	OTLP bool `yaml:"otlp"`
+}
+
+var logFormats = []string{"json", "text"}
+
+func defaultLogConfig() LogConfig {
+	return LogConfig{
+		Level:     "info",
+		Format:    "json",
+		Output:    "stdout",
+		AddSource: true,
+		OTLP:      true,
+	}
+}
+
+func (c *LogConfig) readEnv(r *envReader) {
+	r.string("LOG_LEVEL", &c.Level)
+	r.string("LOG_FORMAT", &c.Format)
+	r.string("LOG_OUTPUT", &c.Output)
+	r.bool("LOG_ADD_SOURCE", &c.AddSource)
+	r.bool("LOG_OTLP", &c.OTLP)
+}
+
+// SlogLevel returns the parsed Level, the config is
+// validated so it is only called with a valid one.
+func (c LogConfig) SlogLevel() slog.Level {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(c.Level))
+	return level
+}
+
+func (c LogConfig) validate() []error {
+	var errs []error
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		errs = append(errs, invalid("LOG_LEVEL", "%q is not one of debug, info, warn or error", c.Level))
+	}
+	if !slices.Contains(logFormats, c.Format) {
+		errs = append(errs, invalid("LOG_FORMAT", "%q is not one of %v", c.Format, logFormats))
+	}
+	if c.Output == "" {
+		errs = append(errs, invalid("LOG_OUTPUT", "must not be empty"))
+	}
+	return errs
+}
@@ -1,5 +0,0 @@
-package config
-
-func GetOtelCollectorConfig() string {
-	return getEnv("OTEL_COLLECTOR", "")
-}
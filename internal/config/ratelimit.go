@@ -0,0 +1,83 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/Oloruntobi1/grey/internal/ratelimit"
+)
+
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Store is memory for a single instance
+	// or postgres to share limits between replicas.
+	Store string `yaml:"store"`
+	// Default applies to routes and principals without
+	// their own limit, written as count/period[:burst].
+	Default string `yaml:"default"`
+	// Routes holds pattern=limit pairs separated by
+	// semicolons, e.g. "POST /v1/users=5/m;POST /v1/wallets=5/m".
+	// The deprecated /api routes share the limit of their successor.
+	Routes string `yaml:"routes"`
+	// Principals holds service:<name>=limit, key:<api key>=limit
+	// or ip:<address>=limit pairs separated by semicolons.
+	Principals string `yaml:"principals"`
+	// TrustProxy takes the client IP from the last X-Forwarded-For
+	// entry, only enable it behind a proxy that appends to the header.
+	TrustProxy bool `yaml:"trust_proxy"`
+}
+
+func defaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Enabled: true,
+		Store:   "memory",
+		Default: "20/s:40",
+	}
+}
+
+func (c *RateLimitConfig) readEnv(r *envReader) {
+	r.bool("RATE_LIMIT_ENABLED", &c.Enabled)
+	r.string("RATE_LIMIT_STORE", &c.Store)
+	r.string("RATE_LIMIT_DEFAULT", &c.Default)
+	r.string("RATE_LIMIT_ROUTES", &c.Routes)
+	r.string("RATE_LIMIT_PRINCIPALS", &c.Principals)
+	r.bool("RATE_LIMIT_TRUST_PROXY", &c.TrustProxy)
+}
+
+func (c RateLimitConfig) validate() []error {
+	if !c.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if c.Store != "memory" && c.Store != "postgres" {
+		errs = append(errs, invalid("RATE_LIMIT_STORE", "%q is not one of memory or postgres", c.Store))
+	}
+	if _, err := ratelimit.ParseLimit(c.Default); err != nil {
+		errs = append(errs, invalid("RATE_LIMIT_DEFAULT", "%v", err))
+	}
+	routes, err := ratelimit.ParseLimits(c.Routes)
+	if err != nil {
+		errs = append(errs, invalid("RATE_LIMIT_ROUTES", "%v", err))
+	}
+	for pattern := range routes {
+		if _, path, _ := strings.Cut(pattern, " "); strings.HasPrefix(path, "/api/") {
+			errs = append(errs, invalid("RATE_LIMIT_ROUTES", "%s is deprecated and limited as its /v1 successor, name that instead", pattern))
+		}
+	}
+	if _, err := ratelimit.ParseLimits(c.Principals); err != nil {
+		errs = append(errs, invalid("RATE_LIMIT_PRINCIPALS", "%v", err))
+	}
+	return errs
+}
+
+// redacted hides the API keys named in Principals.
+func (c RateLimitConfig) redacted() RateLimitConfig {
+	pairs := strings.Split(c.Principals, ";")
+	for i, pair := range pairs {
+		if name, limit, found := strings.Cut(pair, "="); found && strings.HasPrefix(strings.TrimSpace(name), "key:") {
+			pairs[i] = "key:" + redacted + "=" + limit
+		}
+	}
+	c.Principals = strings.Join(pairs, ";")
+	return c
+}
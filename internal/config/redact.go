@@ -0,0 +1,70 @@
+package config
+
+import (
+	"slices"
+
+	"github.com/Oloruntobi1/grey/pkg/redact"
+)
+
+type RedactConfig struct {
+	// Mode is how personal data is replaced in logs, spans
+	// and audit records: mask, hash or drop.
+	Mode redact.Mode `yaml:"mode"`
+	// Fields are the log keys, span attributes and columns
+	// that are redacted whatever their value.
+	Fields []string `yaml:"fields"`
+	// Patterns name the values that are redacted wherever they
+	// appear, including inside messages and errors.
+	Patterns []string `yaml:"patterns"`
+	// HashKey keys the hashes, it is required to hash.
+	HashKey string `yaml:"hash_key"`
+}
+
+func defaultRedactConfig() RedactConfig {
+	return RedactConfig{
+		Mode:     redact.Mask,
+		Fields:   []string{"email", "password", "secret", "token", "authorization", "api_key"},
+		Patterns: []string{"email"},
+	}
+}
+
+func (c *RedactConfig) readEnv(r *envReader) {
+	r.string("REDACT_MODE", (*string)(&c.Mode))
+	r.list("REDACT_FIELDS", &c.Fields)
+	r.list("REDACT_PATTERNS", &c.Patterns)
+	r.string("REDACT_HASH_KEY", &c.HashKey)
+}
+
+// Policy builds the redaction policy, the config is validated
+// so it only fails on settings that were not.
+func (c RedactConfig) Policy() (*redact.Policy, error) {
+	return redact.New(redact.Options{
+		Mode:     c.Mode,
+		Fields:   c.Fields,
+		Patterns: c.Patterns,
+		HashKey:  c.HashKey,
+	})
+}
+
+func (c RedactConfig) validate() []error {
+	var errs []error
+	if !slices.Contains(redact.Modes, c.Mode) {
+		errs = append(errs, invalid("REDACT_MODE", "%q is not one of %v", c.Mode, redact.Modes))
+	}
+	if c.Mode == redact.Hash && c.HashKey == "" {
+		errs = append(errs, invalid("REDACT_HASH_KEY", "is required when REDACT_MODE is %s", redact.Hash))
+	}
+	for _, name := range c.Patterns {
+		if _, ok := redact.Patterns[name]; !ok {
+			errs = append(errs, invalid("REDACT_PATTERNS", "%q is not a known pattern", name))
+		}
+	}
+	return errs
+}
+
+func (c RedactConfig) redacted() RedactConfig {
+	if c.HashKey != "" {
+		c.HashKey = redacted
+	}
+	return c
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SecretSource resolves the reference given in a <key>_FILE
+// variable to the secret it points at.
+type SecretSource interface {
+	Secret(ref string) (string, error)
+}
+
+// FileSecrets reads secrets from local files such as the ones Docker
+// mounts under /run/secrets. Relative references are resolved against
+// Dir, or the working directory when Dir is empty.
+type FileSecrets struct {
+	Dir string
+}
+
+func (s FileSecrets) Secret(ref string) (string, error) {
+	path := ref
+	if !filepath.IsAbs(path) && s.Dir != "" {
+		path = filepath.Join(s.Dir, path)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+	// echo and most editors end the file with a newline
+	// that is not part of the secret
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
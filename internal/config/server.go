@@ -0,0 +1,100 @@
+package config
+
+import (
+	"slices"
+	"strconv"
+	"time"
+)
+
+type ServerConfig struct {
+	// Addr is where the HTTP server listens.
+	Addr     string `yaml:"addr"`
+	GRPCPort string `yaml:"grpc_port"`
+	// AdminAddr serves operational endpoints such as /metrics and
+	// /log/level, away from the public API. They are unauthenticated
+	// so it defaults to loopback only. Empty disables it.
+	AdminAddr string `yaml:"admin_addr"`
+	// MaxBodyBytes caps the size of JSON request bodies.
+	MaxBodyBytes int64      `yaml:"max_body_bytes"`
+	CORS         CORSConfig `yaml:"cors"`
+	HSTS         HSTSConfig `yaml:"hsts"`
+}
+
+type CORSConfig struct {
+	// AllowedOrigins lists the browser origins allowed to call the
+	// API, "*" allows any. CORS headers are not sent when it is empty.
+	AllowedOrigins   []string `yaml:"allowed_origins"`
+	AllowedMethods   []string `yaml:"allowed_methods"`
+	AllowedHeaders   []string `yaml:"allowed_headers"`
+	AllowCredentials bool     `yaml:"allow_credentials"`
+	// MaxAge is how long browsers may cache preflight responses.
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
+type HSTSConfig struct {
+	// MaxAge is sent in Strict-Transport-Security, zero disables it.
+	MaxAge            time.Duration `yaml:"max_age"`
+	IncludeSubdomains bool          `yaml:"include_subdomains"`
+}
+
+func defaultServerConfig() ServerConfig {
+	return ServerConfig{
+		Addr:         ":9191",
+		GRPCPort:     "9090",
+		AdminAddr:    "127.0.0.1:6060",
+		MaxBodyBytes: 1 << 20,
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "POST", "PATCH", "DELETE"},
+			AllowedHeaders: []string{
+				"Content-Type", "If-Match", "Idempotency-Key", "X-API-Key", "traceparent", "tracestate",
+			},
+			MaxAge: 10 * time.Minute,
+		},
+		HSTS: HSTSConfig{
+			MaxAge: 180 * 24 * time.Hour,
+		},
+	}
+}
+
+func (c *ServerConfig) readEnv(r *envReader) {
+	r.string("HTTP_ADDR", &c.Addr)
+	r.string("GRPC_PORT", &c.GRPCPort)
+	r.string("ADMIN_ADDR", &c.AdminAddr)
+	r.int64("HTTP_MAX_BODY_BYTES", &c.MaxBodyBytes)
+	r.list("CORS_ALLOWED_ORIGINS", &c.CORS.AllowedOrigins)
+	r.list("CORS_ALLOWED_METHODS", &c.CORS.AllowedMethods)
+	r.list("CORS_ALLOWED_HEADERS", &c.CORS.AllowedHeaders)
+	r.bool("CORS_ALLOW_CREDENTIALS", &c.CORS.AllowCredentials)
+	r.duration("CORS_MAX_AGE", &c.CORS.MaxAge)
+	r.duration("HSTS_MAX_AGE", &c.HSTS.MaxAge)
+	r.bool("HSTS_INCLUDE_SUBDOMAINS", &c.HSTS.IncludeSubdomains)
+}
+
+func (c ServerConfig) validate() []error {
+	var errs []error
+	if c.Addr == "" {
+		errs = append(errs, invalid("HTTP_ADDR", "must not be empty"))
+	}
+	if !validPort(c.GRPCPort) {
+		errs = append(errs, invalid("GRPC_PORT", "%q is not a port number", c.GRPCPort))
+	}
+	if c.MaxBodyBytes <= 0 {
+		errs = append(errs, invalid("HTTP_MAX_BODY_BYTES", "must be positive"))
+	}
+	// middleware.NewCORS refuses the same, this reports it with the config key
+	if c.CORS.AllowCredentials && slices.Contains(c.CORS.AllowedOrigins, "*") {
+		errs = append(errs, invalid("CORS_ALLOW_CREDENTIALS", "cannot be enabled when CORS_ALLOWED_ORIGINS contains *"))
+	}
+	if c.CORS.MaxAge < 0 {
+		errs = append(errs, invalid("CORS_MAX_AGE", "must not be negative"))
+	}
+	if c.HSTS.MaxAge < 0 {
+		errs = append(errs, invalid("HSTS_MAX_AGE", "must not be negative"))
+	}
+	return errs
+}
+
+func validPort(s string) bool {
+	port, err := strconv.Atoi(s)
+	return err == nil && port > 0 && port < 1<<16
+}
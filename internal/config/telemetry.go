@@ -0,0 +1,116 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"time"
+
+	"github.com/Oloruntobi1/grey/pkg/tracer"
+)
+
+type TelemetryConfig struct {
+	ServiceName string `yaml:"service_name"`
+	// ServiceVersion defaults to the VCS revision of the build.
+	ServiceVersion string `yaml:"service_version"`
+	// Environment is reported as deployment.environment.
+	Environment string `yaml:"environment"`
+
+	// CollectorEndpoint is the host:port of the OTLP collector.
+	CollectorEndpoint string `yaml:"collector_endpoint"`
+	// CollectorInsecure sends telemetry to the collector in plaintext.
+	CollectorInsecure bool `yaml:"collector_insecure"`
+	// CollectorCAFile verifies the collector certificate,
+	// the system roots are used when it is empty.
+	CollectorCAFile string `yaml:"collector_ca_file"`
+	// CollectorHeaders are sent with every export, typically
+	// to authenticate with a hosted collector.
+	CollectorHeaders map[string]string `yaml:"collector_headers"`
+
+	// TracesExporter is otlphttp, otlpgrpc, stdout or none.
+	TracesExporter string `yaml:"traces_exporter"`
+	// TracesSampleRatio is the share of new traces that are
+	// sampled, traces started by a caller follow its decision.
+	TracesSampleRatio float64 `yaml:"traces_sample_ratio"`
+
+	// MetricsExporters lists where metrics go: otlp pushes them to the
+	// collector, prometheus serves them at /metrics on the admin address.
+	MetricsExporters []string `yaml:"metrics_exporters"`
+	// MetricsInterval is how often metrics are pushed over OTLP.
+	MetricsInterval time.Duration `yaml:"metrics_interval"`
+}
+
+var (
+	metricsExporters = []string{"otlp", "prometheus"}
+	tracesExporters  = []string{tracer.ExporterOTLPHTTP, tracer.ExporterOTLPGRPC, tracer.ExporterStdout, tracer.ExporterNone}
+)
+
+func defaultTelemetryConfig() TelemetryConfig {
+	return TelemetryConfig{
+		ServiceName:       "grey-wallet-application",
+		CollectorInsecure: true,
+		TracesExporter:    tracer.ExporterOTLPHTTP,
+		TracesSampleRatio: 1,
+		MetricsExporters:  []string{"otlp"},
+		MetricsInterval:   30 * time.Second,
+	}
+}
+
+func (c *TelemetryConfig) readEnv(r *envReader) {
+	r.string("OTEL_SERVICE_NAME", &c.ServiceName)
+	r.string("SERVICE_VERSION", &c.ServiceVersion)
+	r.string("DEPLOYMENT_ENVIRONMENT", &c.Environment)
+	r.string("OTEL_COLLECTOR", &c.CollectorEndpoint)
+	r.bool("OTEL_COLLECTOR_INSECURE", &c.CollectorInsecure)
+	r.string("OTEL_COLLECTOR_CA_FILE", &c.CollectorCAFile)
+	r.stringMap("OTEL_COLLECTOR_HEADERS", &c.CollectorHeaders)
+	r.string("TRACES_EXPORTER", &c.TracesExporter)
+	r.float64("TRACES_SAMPLE_RATIO", &c.TracesSampleRatio)
+	r.list("METRICS_EXPORTERS", &c.MetricsExporters)
+	r.duration("METRICS_INTERVAL", &c.MetricsInterval)
+}
+
+// MetricsExporter reports whether metrics are sent to the named exporter.
+func (c TelemetryConfig) MetricsExporter(name string) bool {
+	return slices.Contains(c.MetricsExporters, name)
+}
+
+func (c TelemetryConfig) validate() []error {
+	var errs []error
+	if c.ServiceName == "" {
+		errs = append(errs, invalid("OTEL_SERVICE_NAME", "must not be empty"))
+	}
+	if c.CollectorCAFile != "" {
+		if c.CollectorInsecure {
+			errs = append(errs, invalid("OTEL_COLLECTOR_CA_FILE", "cannot be set while OTEL_COLLECTOR_INSECURE is true"))
+		}
+		if _, err := os.Stat(c.CollectorCAFile); err != nil {
+			errs = append(errs, invalid("OTEL_COLLECTOR_CA_FILE", "%v", err))
+		}
+	}
+	if !slices.Contains(tracesExporters, c.TracesExporter) {
+		errs = append(errs, invalid("TRACES_EXPORTER", "%q is not one of %v", c.TracesExporter, tracesExporters))
+	}
+	if c.TracesSampleRatio < 0 || c.TracesSampleRatio > 1 {
+		errs = append(errs, invalid("TRACES_SAMPLE_RATIO", "%v is not between 0 and 1", c.TracesSampleRatio))
+	}
+	for _, exporter := range c.MetricsExporters {
+		if !slices.Contains(metricsExporters, exporter) {
+			errs = append(errs, invalid("METRICS_EXPORTERS", "%q is not one of %v", exporter, metricsExporters))
+		}
+	}
+	if c.MetricsInterval <= 0 {
+		errs = append(errs, invalid("METRICS_INTERVAL", "must be positive"))
+	}
+	return errs
+}
+
+func (c TelemetryConfig) redacted() TelemetryConfig {
+	if len(c.CollectorHeaders) > 0 {
+		headers := make(map[string]string, len(c.CollectorHeaders))
+		for name := range c.CollectorHeaders {
+			headers[name] = redacted
+		}
+		c.CollectorHeaders = headers
+	}
+	return c
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+type TLSConfig struct {
+	// CertFile and KeyFile enable HTTPS when both are set.
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// ClientCAFile is the CA bundle client certificates must chain to.
+	ClientCAFile string `yaml:"client_ca_file"`
+	// ClientAuth is none, optional or require. It defaults to
+	// require when a client CA is configured and none otherwise.
+	ClientAuth string `yaml:"client_auth"`
+	// ClientPrincipals maps client certificate common names to service principals.
+	ClientPrincipals map[string]string `yaml:"client_principals"`
+	// AdminServices are the service principals allowed to call
+	// the admin routes, nobody can when it is empty.
+	AdminServices []string `yaml:"admin_services"`
+	// ReloadInterval is how often the certificate files are checked for changes.
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+}
+
+func defaultTLSConfig() TLSConfig {
+	return TLSConfig{
+		ReloadInterval: 30 * time.Second,
+	}
+}
+
+func (c *TLSConfig) readEnv(r *envReader) {
+	r.string("TLS_CERT_FILE", &c.CertFile)
+	r.string("TLS_KEY_FILE", &c.KeyFile)
+	r.string("TLS_CLIENT_CA_FILE", &c.ClientCAFile)
+	r.string("TLS_CLIENT_AUTH", &c.ClientAuth)
+	r.stringMap("TLS_CLIENT_PRINCIPALS", &c.ClientPrincipals)
+	r.list("TLS_ADMIN_SERVICES", &c.AdminServices)
+	r.duration("TLS_RELOAD_INTERVAL", &c.ReloadInterval)
+
+	if c.ClientAuth == "" {
+		c.ClientAuth = "none"
+		if c.ClientCAFile != "" {
+			c.ClientAuth = "require"
+		}
+	}
+}
+
+// Enabled reports whether the HTTP server should serve TLS.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+func (c TLSConfig) validate() []error {
+	var errs []error
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		errs = append(errs, invalid("TLS_CERT_FILE", "must be set together with TLS_KEY_FILE"))
+	}
+	for _, f := range []struct{ key, file string }{
+		{"TLS_CERT_FILE", c.CertFile},
+		{"TLS_KEY_FILE", c.KeyFile},
+		{"TLS_CLIENT_CA_FILE", c.ClientCAFile},
+	} {
+		if f.file == "" {
+			continue
+		}
+		if _, err := os.Stat(f.file); err != nil {
+			errs = append(errs, invalid(f.key, "%v", err))
+		}
+	}
+
+	switch c.ClientAuth {
+	case "none":
+	case "optional", "require":
+		if c.ClientCAFile == "" {
+			errs = append(errs, invalid("TLS_CLIENT_AUTH", "%s requires TLS_CLIENT_CA_FILE", c.ClientAuth))
+		}
+	default:
+		errs = append(errs, invalid("TLS_CLIENT_AUTH", "%q is not one of none, optional or require", c.ClientAuth))
+	}
+	if len(c.AdminServices) > 0 && c.ClientCAFile == "" {
+		errs = append(errs, invalid("TLS_ADMIN_SERVICES", "requires TLS_CLIENT_CA_FILE"))
+	}
+	if c.ClientCAFile != "" && !c.Enabled() {
+		errs = append(errs, invalid("TLS_CLIENT_CA_FILE", "requires TLS_CERT_FILE and TLS_KEY_FILE"))
+	}
+	if c.ReloadInterval <= 0 {
+		errs = append(errs, invalid("TLS_RELOAD_INTERVAL", "must be positive"))
+	}
+	return errs
+}
@@ -1 +1,3 @@
-DROP TABLE IF EXISTS users;
\ No newline at end of file
+DROP TABLE IF EXISTS users_logs;
+DROP TABLE IF EXISTS users;
+DROP FUNCTION IF EXISTS users_trigger_function();
\ No newline at end of file
@@ -1 +1,3 @@
-DROP TABLE IF EXISTS wallets;
\ No newline at end of file
+DROP TABLE IF EXISTS wallets_logs;
+DROP TABLE IF EXISTS wallets;
+DROP FUNCTION IF EXISTS wallets_trigger_function();
\ No newline at end of file
@@ -1 +1,3 @@
-DROP TABLE IF EXISTS transactions;
\ No newline at end of file
+DROP TABLE IF EXISTS transactions_logs;
+DROP TABLE IF EXISTS transactions;
+DROP FUNCTION IF EXISTS transactions_trigger_function();
\ No newline at end of file
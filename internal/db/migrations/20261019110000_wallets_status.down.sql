@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS wallets_status_guard_trigger ON wallets;
+DROP FUNCTION IF EXISTS wallets_status_guard();
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_status_check;
+ALTER TABLE wallets
+    DROP COLUMN IF EXISTS status_reason,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,28 @@
+ALTER TABLE wallets
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS status_reason TEXT;
+
+ALTER TABLE wallets
+    ADD CONSTRAINT wallets_status_check
+    CHECK (status IN ('active', 'frozen', 'debit_blocked', 'credit_blocked', 'closed'));
+
+-- Every path that moves money ends up changing wallets.balance,
+-- so the status is enforced here rather than in each caller.
+CREATE OR REPLACE FUNCTION wallets_status_guard() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.balance < OLD.balance AND OLD.status IN ('frozen', 'debit_blocked', 'closed') THEN
+        RAISE EXCEPTION 'wallet % does not allow debits while %', OLD.id, OLD.status
+            USING ERRCODE = 'GW001';
+    END IF;
+    IF NEW.balance > OLD.balance AND OLD.status IN ('frozen', 'credit_blocked', 'closed') THEN
+        RAISE EXCEPTION 'wallet % does not allow credits while %', OLD.id, OLD.status
+            USING ERRCODE = 'GW002';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER wallets_status_guard_trigger
+BEFORE UPDATE OF balance ON wallets
+FOR EACH ROW
+EXECUTE FUNCTION wallets_status_guard();
@@ -0,0 +1,147 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	db "github.com/Oloruntobi1/grey/internal/db/sqlc"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationLockKey is the advisory lock every replica contends on
+// before migrating. golang-migrate derives its own key from the
+// database name, so the two locks never collide.
+const migrationLockKey int64 = 0x67726579
+
+const (
+	lockPollInterval   = 500 * time.Millisecond
+	schemaPollInterval = 2 * time.Second
+)
+
+// ErrSchemaVersionMismatch is returned when the database is dirty or
+// behind the latest migration embedded in the binary.
+var ErrSchemaVersionMismatch = errors.New("schema version mismatch")
+
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// SchemaVersion returns the version recorded by golang-migrate.
+// A database that was never migrated reports version 0.
+func SchemaVersion(ctx context.Context, q queryRower) (uint, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := q.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, db.ErrRecordNotFound) || db.ErrorCode(err) == db.UndefinedTable {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("unable to read schema version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
+
+// checkSchemaVersion fails unless the database is clean
+// and at least at the expected version.
+func checkSchemaVersion(ctx context.Context, q queryRower, expected uint) (uint, error) {
+	version, dirty, err := SchemaVersion(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	if dirty || version < expected {
+		return version, fmt.Errorf("%w: have %d (dirty: %t), want %d", ErrSchemaVersionMismatch, version, dirty, expected)
+	}
+	return version, nil
+}
+
+// withMigrationLock runs fn while holding the migration advisory lock.
+// won reports whether the lock was free on the first attempt, meaning
+// no other replica was migrating when we arrived.
+func withMigrationLock(
+	ctx context.Context,
+	dbInstance *pgxpool.Pool,
+	logger *slog.Logger,
+	timeout time.Duration,
+	fn func(conn *pgxpool.Conn, won bool) error,
+) error {
+	// Session level advisory locks belong to a connection,
+	// so hold on to one for the whole critical section.
+	conn, err := dbInstance.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to acquire connection for migration lock: %w", err)
+	}
+	defer conn.Release()
+
+	lockCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	won := true
+	for {
+		var locked bool
+		err := conn.QueryRow(lockCtx, "SELECT pg_try_advisory_lock($1)", migrationLockKey).Scan(&locked)
+		if err != nil {
+			return fmt.Errorf("unable to take migration lock: %w", err)
+		}
+		if locked {
+			break
+		}
+
+		if won {
+			logger.Info("another replica is migrating, waiting for it to finish")
+		}
+		won = false
+
+		select {
+		case <-lockCtx.Done():
+			return fmt.Errorf("timed out after %s waiting for migration lock: %w", timeout, lockCtx.Err())
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	defer func() {
+		// The caller's context may be done by now; the lock
+		// must still be released before the connection returns
+		// to the pool, otherwise the connection is discarded.
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.Exec(unlockCtx, "SELECT pg_advisory_unlock($1)", migrationLockKey); err != nil {
+			logger.Error("failed_to_release_migration_lock", slog.Any("err", err))
+			_ = conn.Conn().Close(unlockCtx)
+		}
+	}()
+
+	return fn(conn, won)
+}
+
+// WaitForSchemaVersion blocks until the embedded latest migration
+// version has been applied by someone else, or timeout elapses.
+func WaitForSchemaVersion(ctx context.Context, dbInstance *pgxpool.Pool, logger *slog.Logger, timeout time.Duration) error {
+	expected, err := LatestVersion()
+	if err != nil {
+		return err
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		version, err := checkSchemaVersion(waitCtx, dbInstance, expected)
+		if err == nil {
+			logger.Info("schema version present", slog.Uint64("version", uint64(version)))
+			return nil
+		}
+		logger.Info("waiting for schema version", slog.Uint64("expected", uint64(expected)), slog.Any("err", err))
+
+		select {
+		case <-waitCtx.Done():
+			return fmt.Errorf("schema version %d not present after %s: %w", expected, timeout, err)
+		case <-time.After(schemaPollInterval):
+		}
+	}
+}
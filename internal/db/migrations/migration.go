@@ -4,45 +4,180 @@ import (
 	"context"
 	"embed"
 	"fmt"
-	"log"
+	"io/fs"
 	"log/slog"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/Oloruntobi1/grey/pkg/redact"
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 //go:embed *.sql
-var fs embed.FS
+var migrationFS embed.FS
 
-func RunDBMigration(ctx context.Context, dbInstance *pgxpool.Pool, logger *slog.Logger) error {
-	d, err := iofs.New(fs, ".")
+// Migration describes a single migration version found
+// in the embedded migration files.
+type Migration struct {
+	Version uint
+	Name    string
+	HasDown bool
+}
+
+// NewMigrate returns a migrate instance reading from the
+// embedded migration files and applying them to connString.
+func NewMigrate(connString string) (*migrate.Migrate, error) {
+	d, err := iofs.New(migrationFS, ".")
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("cannot read embedded migrations: %v", err)
 	}
-	fmt.Println(dbInstance.Config().ConnString())
-	migration, err := migrate.NewWithSourceInstance("iofs", d, dbInstance.Config().ConnString())
+
+	migration, err := migrate.NewWithSourceInstance("iofs", d, connString)
 	if err != nil {
-		return fmt.Errorf("cannot create new migrate instance: %v", err)
+		return nil, fmt.Errorf("cannot create new migrate instance: %v", err)
 	}
 
-	if err = migration.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("failed to run migrate up: %v", err)
+	return migration, nil
+}
+
+// ListMigrations returns every embedded migration ordered by version.
+func ListMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFS, ".")
+	if err != nil {
+		return nil, err
 	}
 
+	byVersion := make(map[uint]*Migration)
+	for _, e := range entries {
+		// file names look like 20240604103435_users.up.sql
+		versionPart, rest, ok := strings.Cut(e.Name(), "_")
+		if !ok {
+			continue
+		}
+		version, err := strconv.ParseUint(versionPart, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		name, direction, ok := strings.Cut(strings.TrimSuffix(rest, ".sql"), ".")
+		if !ok {
+			continue
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: name}
+			byVersion[uint(version)] = m
+		}
+		if direction == "down" {
+			m.HasDown = true
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// LatestVersion returns the highest embedded migration version.
+func LatestVersion() (uint, error) {
+	migrations, err := ListMigrations()
+	if err != nil {
+		return 0, err
+	}
+	if len(migrations) == 0 {
+		return 0, fmt.Errorf("no embedded migrations found")
+	}
+	return migrations[len(migrations)-1].Version, nil
+}
+
+// RunDBMigration applies pending migrations and the audit log tables,
+// whose records are redacted with the policy.
+// Replicas starting together serialise on an advisory lock; the ones
+// that lose the race wait for the winner and only verify that the
+// expected schema version is present.
+func RunDBMigration(ctx context.Context, dbInstance *pgxpool.Pool, logger *slog.Logger, lockTimeout time.Duration, policy *redact.Policy) error {
+	expected, err := LatestVersion()
+	if err != nil {
+		return err
+	}
+
+	return withMigrationLock(ctx, dbInstance, logger, lockTimeout, func(conn *pgxpool.Conn, won bool) error {
+		if !won {
+			version, err := checkSchemaVersion(ctx, conn, expected)
+			if err != nil {
+				return err
+			}
+			logger.Info("db migrated by another replica", slog.Uint64("version", uint64(version)))
+			return nil
+		}
+
+		migration, err := NewMigrate(dbInstance.Config().ConnString())
+		if err != nil {
+			return err
+		}
+		defer migration.Close()
+
+		if err = migration.Up(); err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("failed to run migrate up: %v", err)
+		}
+
+		// the DDL runs on the connection holding the lock, a pool
+		// of a single connection would otherwise wait for itself
+		if err := CreateAuditLogTables(ctx, conn, policy); err != nil {
+			return err
+		}
+
+		logger.Info("db migrated successfully")
+		return nil
+	})
+}
+
+// auditExcludedTables are not business data and get no log table.
+var auditExcludedTables = map[string]bool{
+	"schema_migrations":  true,
+	"rate_limit_buckets": true,
+	"redact_settings":    true,
+}
+
+// txBeginner is a pool or one of its connections.
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// CreateAuditLogTables creates a <table>_logs table for every
+// migrated table along with the triggers that populate it. The fields
+// of the policy are redacted before the rows are recorded.
+func CreateAuditLogTables(ctx context.Context, dbInstance txBeginner, policy *redact.Policy) error {
 	// Start a new transaction.
 	tx, err := dbInstance.Begin(ctx)
 	if err != nil {
-		log.Fatal(fmt.Errorf("unable to begin transaction: %w", err))
+		return fmt.Errorf("unable to begin transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
+	for _, st := range policy.AuditSetup() {
+		if _, err := tx.Exec(ctx, st.SQL, st.Args...); err != nil {
+			return fmt.Errorf("unable to set up audit redaction: %w", err)
+		}
+	}
+
 	rows, err := tx.Query(ctx, "SELECT table_name FROM information_schema.tables WHERE table_schema = 'public'")
 	if err != nil {
-		log.Fatal(fmt.Errorf("unable to query db: %w", err))
+		return fmt.Errorf("unable to query db: %w", err)
 	}
 
 	defer rows.Close()
@@ -52,19 +187,18 @@ func RunDBMigration(ctx context.Context, dbInstance *pgxpool.Pool, logger *slog.
 	for rows.Next() {
 		var tableName string
 		if err := rows.Scan(&tableName); err != nil {
-			log.Fatal(fmt.Errorf("unable to scan row: %w", err))
+			return fmt.Errorf("unable to scan row: %w", err)
 		}
-		fmt.Println("Table Name:", tableName)
 
 		tableNames = append(tableNames, tableName)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Fatal(fmt.Errorf("error while iterating over rows: %w", err))
+		return fmt.Errorf("error while iterating over rows: %w", err)
 	}
 
 	for _, t := range tableNames {
-		if t == "schema_migrations" || strings.Contains(t, "_logs") {
+		if auditExcludedTables[t] || strings.Contains(t, "_logs") {
 			continue
 		}
 
@@ -86,7 +220,7 @@ func RunDBMigration(ctx context.Context, dbInstance *pgxpool.Pool, logger *slog.
 
 		_, err := tx.Exec(ctx, query)
 		if err != nil {
-			log.Fatal(fmt.Errorf("table: %s. unable to execute SQL statement: %w", t, err))
+			return fmt.Errorf("table: %s. unable to execute SQL statement: %w", t, err)
 		}
 
 		// trigger function
@@ -101,11 +235,11 @@ func RunDBMigration(ctx context.Context, dbInstance *pgxpool.Pool, logger *slog.
 			 IF TG_OP = 'INSERT' THEN
 				 log_action := 'insert';
 				 log_data_before := '{}'::JSONB;
-				 log_data_after := to_jsonb(NEW) - 'log_id';
+				 log_data_after := %s;
 			 ELSIF TG_OP = 'UPDATE' THEN
 				 log_action := 'update';
-				 log_data_before := to_jsonb(OLD) - 'log_id';
-				 log_data_after := to_jsonb(NEW) - 'log_id';
+				 log_data_before := %s;
+				 log_data_after := %s;
 			 END IF;
 			 
 			 INSERT INTO %s_logs (%s_id, date, action, data_before, data_after)
@@ -113,11 +247,15 @@ func RunDBMigration(ctx context.Context, dbInstance *pgxpool.Pool, logger *slog.
  
 			 RETURN NEW;
 		 END;
-		 $$ LANGUAGE plpgsql;`, t, t, t, tbl)
+		 $$ LANGUAGE plpgsql;`, t, t,
+			policy.AuditJSON("to_jsonb(NEW) - 'log_id'"),
+			policy.AuditJSON("to_jsonb(OLD) - 'log_id'"),
+			policy.AuditJSON("to_jsonb(NEW) - 'log_id'"),
+			t, tbl)
 
 		_, err = tx.Exec(ctx, triggerFunctionQuery)
 		if err != nil {
-			log.Fatal(fmt.Errorf("table: %s. unable to execute trigger function SQL statement: %w", t, err))
+			return fmt.Errorf("table: %s. unable to execute trigger function SQL statement: %w", t, err)
 		}
 
 		insertTriggerQuery := fmt.Sprintf(`
@@ -165,9 +303,8 @@ func RunDBMigration(ctx context.Context, dbInstance *pgxpool.Pool, logger *slog.
 	// Commit the transaction if everything is successful.
 	err = tx.Commit(ctx)
 	if err != nil {
-		log.Fatal(fmt.Errorf("unable to commit transaction: %w", err))
+		return fmt.Errorf("unable to commit transaction: %w", err)
 	}
 
-	logger.Info("db migrated successfully")
 	return nil
 }
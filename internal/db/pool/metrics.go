@@ -0,0 +1,92 @@
+package pool
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Roles tell the pools of one process apart in their metrics.
+const (
+	RolePrimary = "primary"
+	RoleReplica = "replica"
+)
+
+// RegisterMetrics reports the statistics of p every time the meter
+// provider collects, every series carrying the role of the pool.
+// Unregister the returned registration before closing the pool.
+func RegisterMetrics(p *pgxpool.Pool, role string) (metric.Registration, error) {
+	meter := otel.Meter("pgxpool")
+
+	roleAttr := attribute.String("role", role)
+	withRole := metric.WithAttributes(roleAttr)
+	stateAcquired := metric.WithAttributes(roleAttr, attribute.String("state", "acquired"))
+	stateIdle := metric.WithAttributes(roleAttr, attribute.String("state", "idle"))
+	stateConstructing := metric.WithAttributes(roleAttr, attribute.String("state", "constructing"))
+
+	conns, err := meter.Int64ObservableGauge(
+		"db.pool.connections",
+		metric.WithDescription("Number of connections in the pool by state."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	maxConns, err := meter.Int64ObservableGauge(
+		"db.pool.connections.max",
+		metric.WithDescription("Maximum number of connections the pool opens."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	acquires, err := meter.Int64ObservableCounter(
+		"db.pool.acquires",
+		metric.WithDescription("Number of connections acquired from the pool."),
+		metric.WithUnit("{acquire}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	waits, err := meter.Int64ObservableCounter(
+		"db.pool.acquire.waits",
+		metric.WithDescription("Number of acquires that had to wait for a connection because none was idle."),
+		metric.WithUnit("{acquire}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	waitDuration, err := meter.Float64ObservableCounter(
+		"db.pool.acquire.duration",
+		metric.WithDescription("Total time spent acquiring connections from the pool."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	canceled, err := meter.Int64ObservableCounter(
+		"db.pool.acquire.canceled",
+		metric.WithDescription("Number of acquires canceled by their context."),
+		metric.WithUnit("{acquire}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		stat := p.Stat()
+
+		o.ObserveInt64(conns, int64(stat.AcquiredConns()), stateAcquired)
+		o.ObserveInt64(conns, int64(stat.IdleConns()), stateIdle)
+		o.ObserveInt64(conns, int64(stat.ConstructingConns()), stateConstructing)
+		o.ObserveInt64(maxConns, int64(stat.MaxConns()), withRole)
+		o.ObserveInt64(acquires, stat.AcquireCount(), withRole)
+		o.ObserveInt64(waits, stat.EmptyAcquireCount(), withRole)
+		o.ObserveFloat64(waitDuration, stat.AcquireDuration().Seconds(), withRole)
+		o.ObserveInt64(canceled, stat.CanceledAcquireCount(), withRole)
+		return nil
+	}, conns, maxConns, acquires, waits, waitDuration, canceled)
+}
@@ -0,0 +1,44 @@
+// Package pool builds the pgx connection pool from the typed
+// database config and exports its statistics as metrics.
+package pool
+
+import (
+	"strconv"
+
+	"github.com/Oloruntobi1/grey/internal/config"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NewConfig parses the connection string of cfg and applies the pool
+// settings on top of it. Zero values leave the pgxpool defaults in place.
+func NewConfig(cfg config.DatabaseConfig) (*pgxpool.Config, error) {
+	pgxConfig, err := pgxpool.ParseConfig(cfg.URL())
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Pool.MaxConns > 0 {
+		pgxConfig.MaxConns = cfg.Pool.MaxConns
+	}
+	if cfg.Pool.MinConns > 0 {
+		pgxConfig.MinConns = cfg.Pool.MinConns
+	}
+	if cfg.Pool.MaxConnLifetime > 0 {
+		pgxConfig.MaxConnLifetime = cfg.Pool.MaxConnLifetime
+	}
+	if cfg.Pool.MaxConnIdleTime > 0 {
+		pgxConfig.MaxConnIdleTime = cfg.Pool.MaxConnIdleTime
+	}
+	if cfg.Pool.HealthCheckPeriod > 0 {
+		pgxConfig.HealthCheckPeriod = cfg.Pool.HealthCheckPeriod
+	}
+
+	// Sent in the startup message, so every connection
+	// has it set before the first statement runs.
+	if cfg.StatementTimeout > 0 {
+		pgxConfig.ConnConfig.RuntimeParams["statement_timeout"] =
+			strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+
+	return pgxConfig, nil
+}
@@ -0,0 +1,14 @@
+-- name: ReplicationLag :one
+-- Seconds the replica's replay is behind the primary. A replica that
+-- has replayed everything it received is not behind, however long ago
+-- the primary last wrote, as long as it is still streaming from it; a
+-- replica cut off from the primary would otherwise look current while
+-- it falls behind. -1 means it is not streaming or nothing has been
+-- replayed yet. The receiver status is read from the function behind
+-- pg_stat_wal_receiver, which only shows it to pg_read_all_stats.
+SELECT (CASE
+    WHEN NOT pg_is_in_recovery() THEN 0
+    WHEN NOT EXISTS (SELECT 1 FROM pg_stat_get_wal_receiver() WHERE status = 'streaming') THEN -1
+    WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+    ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), -1)
+END)::float8 AS lag_seconds;
@@ -4,4 +4,70 @@ name,
 email
 ) VALUES (
     $1, $2
-) RETURNING *;
\ No newline at end of file
+) RETURNING *;
+
+-- name: GetUser :one
+SELECT * FROM users
+WHERE id = $1 AND is_deleted = false;
+
+-- name: GetUserIncludingDeleted :one
+SELECT * FROM users
+WHERE id = $1;
+
+-- name: SoftDeleteUser :one
+-- The wallets are locked before their balances and statuses are checked
+-- so no credit or freeze can land between the check and the delete.
+-- Frozen wallets are kept, as SoftDeleteWallet keeps them.
+WITH locked_wallets AS (
+    SELECT wallets.id, wallets.balance, wallets.status FROM wallets
+    WHERE wallets.user_id = $1 AND wallets.is_deleted = false
+    FOR UPDATE
+), deleted_user AS (
+    UPDATE users
+    SET is_deleted = true, deleted_at = now(), updated_at = now(), version = version + 1
+    WHERE users.id = $1 AND users.is_deleted = false
+    AND NOT EXISTS (
+        SELECT 1 FROM locked_wallets
+        WHERE locked_wallets.balance <> 0 OR locked_wallets.status = 'frozen'
+    )
+    RETURNING *
+), deleted_wallets AS (
+    UPDATE wallets
+    SET is_deleted = true, deleted_at = now(), updated_at = now()
+    WHERE wallets.user_id IN (SELECT id FROM deleted_user) AND wallets.is_deleted = false
+    RETURNING wallets.id
+)
+SELECT * FROM deleted_user;
+
+-- name: UserHasFrozenWallet :one
+SELECT EXISTS (
+    SELECT 1 FROM wallets
+    WHERE wallets.user_id = $1 AND wallets.is_deleted = false AND wallets.status = 'frozen'
+);
+
+-- name: RestoreUser :one
+WITH restored_user AS (
+    UPDATE users
+    SET is_deleted = false, deleted_at = NULL, updated_at = now(), version = version + 1
+    WHERE users.id = $1 AND users.is_deleted = true
+    RETURNING *
+), restored_wallets AS (
+    UPDATE wallets
+    SET is_deleted = false, deleted_at = NULL, updated_at = now()
+    FROM users
+    WHERE wallets.user_id = users.id AND users.id = $1 AND users.is_deleted = true
+    AND wallets.deleted_at = users.deleted_at AND wallets.status <> 'closed'
+    RETURNING wallets.id
+)
+SELECT * FROM restored_user;
+
+
+-- name: UpdateUser :one
+UPDATE users
+SET name = COALESCE(sqlc.narg(name), name),
+    email = COALESCE(sqlc.narg(email), email),
+    updated_at = now(),
+    version = version + 1
+WHERE id = sqlc.arg(id) AND is_deleted = false
+AND (sqlc.narg(expected_version)::bigint IS NULL OR version = sqlc.narg(expected_version)::bigint)
+RETURNING *;
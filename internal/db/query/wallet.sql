@@ -2,6 +2,45 @@
 INSERT INTO wallets(
 user_id,
 balance
-) VALUES (
-    $1, $2
-) RETURNING *;
\ No newline at end of file
+)
+SELECT sqlc.arg(user_id)::uuid, sqlc.arg(balance)::numeric
+WHERE EXISTS (
+    SELECT 1 FROM users WHERE users.id = sqlc.arg(user_id)::uuid AND users.is_deleted = false
+) RETURNING *;
+
+-- name: GetWallet :one
+SELECT * FROM wallets
+WHERE id = $1 AND is_deleted = false;
+
+-- name: GetWalletIncludingDeleted :one
+SELECT * FROM wallets
+WHERE id = $1;
+
+-- name: SoftDeleteWallet :one
+-- Frozen wallets are kept until the review that froze them is over.
+UPDATE wallets
+SET is_deleted = true, deleted_at = now(), updated_at = now()
+WHERE id = $1 AND is_deleted = false AND COALESCE(balance, 0) = 0
+AND status <> 'frozen'
+RETURNING *;
+
+-- name: RestoreWallet :one
+-- Closing a wallet is final, deleting it does not undo that.
+UPDATE wallets
+SET is_deleted = false, deleted_at = NULL, updated_at = now()
+WHERE id = $1 AND is_deleted = true AND status <> 'closed'
+RETURNING *;
+
+-- name: UpdateWalletStatus :one
+UPDATE wallets
+SET status = sqlc.arg(status), status_reason = sqlc.arg(reason), updated_at = now()
+WHERE id = sqlc.arg(id) AND is_deleted = false AND status = sqlc.arg(from_status)
+AND (sqlc.arg(status)::text <> 'closed' OR COALESCE(balance, 0) = 0)
+RETURNING *;
+
+-- name: WalletBalancesByStatus :many
+-- Count and total balance of the wallets that are not deleted.
+SELECT status, COUNT(*) AS wallets, COALESCE(SUM(balance), 0)::numeric AS balance
+FROM wallets
+WHERE is_deleted = false
+GROUP BY status;
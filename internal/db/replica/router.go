@@ -0,0 +1,106 @@
+// Package replica routes read only queries to a streaming replica
+// while it keeps up with the primary, and everything else to the primary.
+package replica
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	db "github.com/Oloruntobi1/grey/internal/db/sqlc"
+)
+
+// Router hands out the queries to run against. Reads go to the replica
+// only while its measured lag is within maxLag and the current session
+// has not written yet, so a request always reads its own writes.
+type Router struct {
+	primary db.Querier
+	replica db.Querier
+	maxLag  time.Duration
+	logger  *slog.Logger
+
+	// usable is false until the first lag check
+	// passes and whenever the replica falls behind.
+	usable atomic.Bool
+}
+
+// NewRouter returns a router sending everything to the primary
+// when replica is nil. Run must be started for reads to use the
+// replica at all.
+func NewRouter(primary, replica db.Querier, maxLag time.Duration, logger *slog.Logger) *Router {
+	return &Router{
+		primary: primary,
+		replica: replica,
+		maxLag:  maxLag,
+		logger:  logger,
+	}
+}
+
+// Primary returns the primary queries and records in the session
+// of ctx that it wrote, sending the session's later reads there too.
+func (r *Router) Primary(ctx context.Context) db.Querier {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.wrote.Store(true)
+	}
+	return r.primary
+}
+
+// Reader returns the queries for a read that may see data up to
+// maxLag old.
+func (r *Router) Reader(ctx context.Context) db.Querier {
+	if r.replica == nil || !r.usable.Load() {
+		return r.primary
+	}
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok && s.wrote.Load() {
+		return r.primary
+	}
+	return r.replica
+}
+
+// Run measures the replica lag every interval until ctx is done.
+func (r *Router) Run(ctx context.Context, interval time.Duration) {
+	if r.replica == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		r.checkLag(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Router) checkLag(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, r.maxLag)
+	defer cancel()
+
+	seconds, err := r.replica.ReplicationLag(ctx)
+	if err != nil {
+		if r.usable.Swap(false) {
+			r.logger.WarnContext(ctx, "replica_unavailable", slog.Any("err", err))
+		}
+		return
+	}
+
+	lag := time.Duration(seconds * float64(time.Second))
+	usable := seconds >= 0 && lag <= r.maxLag
+	if r.usable.Swap(usable) == usable {
+		return
+	}
+	switch {
+	case usable:
+		r.logger.InfoContext(ctx, "replica_caught_up", slog.Duration("lag", lag))
+	case seconds < 0:
+		r.logger.WarnContext(ctx, "replica_not_streaming")
+	default:
+		r.logger.WarnContext(ctx, "replica_lagging", slog.Duration("lag", lag), slog.Duration("max_lag", r.maxLag))
+	}
+}
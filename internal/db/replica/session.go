@@ -0,0 +1,31 @@
+package replica
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type sessionKey struct{}
+
+// session remembers whether a request wrote to the primary.
+type session struct {
+	wrote atomic.Bool
+}
+
+// WithSession starts a read-your-writes session, normally one per
+// request. Without one every read may go to the replica.
+func WithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &session{})
+}
+
+// UsePrimary sends every read made with the returned context to the
+// primary, for reads that decide what to write next.
+func UsePrimary(ctx context.Context) context.Context {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.wrote.Store(true)
+		return ctx
+	}
+	s := &session{}
+	s.wrote.Store(true)
+	return context.WithValue(ctx, sessionKey{}, s)
+}
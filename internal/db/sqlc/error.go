@@ -12,6 +12,11 @@ const (
 	UniqueViolation           = "23505"
 	InvalidTextRepresentation = "22P02"
 	CheckViolation            = "23514" // when the CHECK constraint have been used in the DDL
+	UndefinedTable            = "42P01"
+
+	// raised by the wallets_status_guard trigger
+	WalletDebitBlocked  = "GW001"
+	WalletCreditBlocked = "GW002"
 )
 
 var ErrRecordNotFound = pgx.ErrNoRows
@@ -5,10 +5,19 @@
 package db
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type RateLimitBucket struct {
+	Key       string    `json:"key"`
+	Tokens    float64   `json:"tokens"`
+	Allowed   bool      `json:"allowed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type Transaction struct {
 	ID         uuid.UUID          `json:"id"`
 	FromUserID uuid.UUID          `json:"from_user_id"`
@@ -28,14 +37,17 @@ type User struct {
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
 	IsDeleted *bool              `json:"is_deleted"`
+	Version   int64              `json:"version"`
 }
 
 type Wallet struct {
-	ID        uuid.UUID          `json:"id"`
-	UserID    uuid.UUID          `json:"user_id"`
-	Balance   pgtype.Numeric     `json:"balance"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
-	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
-	IsDeleted *bool              `json:"is_deleted"`
+	ID           uuid.UUID          `json:"id"`
+	UserID       uuid.UUID          `json:"user_id"`
+	Balance      pgtype.Numeric     `json:"balance"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	DeletedAt    pgtype.Timestamptz `json:"deleted_at"`
+	IsDeleted    *bool              `json:"is_deleted"`
+	Status       string             `json:"status"`
+	StatusReason *string            `json:"status_reason"`
 }
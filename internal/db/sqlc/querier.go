@@ -6,12 +6,44 @@ package db
 
 import (
 	"context"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
 	CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	CreateWallet(ctx context.Context, arg CreateWalletParams) (Wallet, error)
+	DeleteIdleRateLimitBuckets(ctx context.Context, idleSeconds float64) (int64, error)
+	GetUser(ctx context.Context, id uuid.UUID) (User, error)
+	GetUserIncludingDeleted(ctx context.Context, id uuid.UUID) (User, error)
+	GetWallet(ctx context.Context, id uuid.UUID) (Wallet, error)
+	GetWalletIncludingDeleted(ctx context.Context, id uuid.UUID) (Wallet, error)
+	// Seconds the replica's replay is behind the primary. A replica that
+	// has replayed everything it received is not behind, however long ago
+	// the primary last wrote, as long as it is still streaming from it; a
+	// replica cut off from the primary would otherwise look current while
+	// it falls behind. -1 means it is not streaming or nothing has been
+	// replayed yet. The receiver status is read from the function behind
+	// pg_stat_wal_receiver, which only shows it to pg_read_all_stats.
+	ReplicationLag(ctx context.Context) (float64, error)
+	RestoreUser(ctx context.Context, id uuid.UUID) (RestoreUserRow, error)
+	// Closing a wallet is final, deleting it does not undo that.
+	RestoreWallet(ctx context.Context, id uuid.UUID) (Wallet, error)
+	// The wallets are locked before their balances and statuses are checked
+	// so no credit or freeze can land between the check and the delete.
+	// Frozen wallets are kept, as SoftDeleteWallet keeps them.
+	SoftDeleteUser(ctx context.Context, userID uuid.UUID) (SoftDeleteUserRow, error)
+	// Frozen wallets are kept until the review that froze them is over.
+	SoftDeleteWallet(ctx context.Context, id uuid.UUID) (Wallet, error)
+	// Refills the bucket for the time since its last update, capped at
+	// burst, and takes a token when at least one is available.
+	TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (TakeRateLimitTokenRow, error)
+	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateWalletStatus(ctx context.Context, arg UpdateWalletStatusParams) (Wallet, error)
+	UserHasFrozenWallet(ctx context.Context, userID uuid.UUID) (bool, error)
+	// Count and total balance of the wallets that are not deleted.
+	WalletBalancesByStatus(ctx context.Context) ([]WalletBalancesByStatusRow, error)
 }
 
 var _ Querier = (*Queries)(nil)
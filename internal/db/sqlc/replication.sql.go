@@ -0,0 +1,33 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: replication.sql
+
+package db
+
+import (
+	"context"
+)
+
+const replicationLag = `-- name: ReplicationLag :one
+SELECT (CASE
+    WHEN NOT pg_is_in_recovery() THEN 0
+    WHEN NOT EXISTS (SELECT 1 FROM pg_stat_get_wal_receiver() WHERE status = 'streaming') THEN -1
+    WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+    ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), -1)
+END)::float8 AS lag_seconds
+`
+
+// Seconds the replica's replay is behind the primary. A replica that
+// has replayed everything it received is not behind, however long ago
+// the primary last wrote, as long as it is still streaming from it; a
+// replica cut off from the primary would otherwise look current while
+// it falls behind. -1 means it is not streaming or nothing has been
+// replayed yet. The receiver status is read from the function behind
+// pg_stat_wal_receiver, which only shows it to pg_read_all_stats.
+func (q *Queries) ReplicationLag(ctx context.Context) (float64, error) {
+	row := q.db.QueryRow(ctx, replicationLag)
+	var lag_seconds float64
+	err := row.Scan(&lag_seconds)
+	return lag_seconds, err
+}
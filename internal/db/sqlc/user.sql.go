@@ -7,6 +7,9 @@ package db
 
 import (
 	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createUser = `-- name: CreateUser :one
@@ -15,7 +18,7 @@ name,
 email
 ) VALUES (
     $1, $2
-) RETURNING id, name, email, created_at, updated_at, deleted_at, is_deleted
+) RETURNING id, name, email, created_at, updated_at, deleted_at, is_deleted, version
 `
 
 type CreateUserParams struct {
@@ -34,6 +37,199 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.UpdatedAt,
 		&i.DeletedAt,
 		&i.IsDeleted,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getUser = `-- name: GetUser :one
+SELECT id, name, email, created_at, updated_at, deleted_at, is_deleted, version FROM users
+WHERE id = $1 AND is_deleted = false
+`
+
+func (q *Queries) GetUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, getUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.IsDeleted,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getUserIncludingDeleted = `-- name: GetUserIncludingDeleted :one
+SELECT id, name, email, created_at, updated_at, deleted_at, is_deleted, version FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserIncludingDeleted(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, getUserIncludingDeleted, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.IsDeleted,
+		&i.Version,
+	)
+	return i, err
+}
+
+const restoreUser = `-- name: RestoreUser :one
+WITH restored_user AS (
+    UPDATE users
+    SET is_deleted = false, deleted_at = NULL, updated_at = now(), version = version + 1
+    WHERE users.id = $1 AND users.is_deleted = true
+    RETURNING id, name, email, created_at, updated_at, deleted_at, is_deleted, version
+), restored_wallets AS (
+    UPDATE wallets
+    SET is_deleted = false, deleted_at = NULL, updated_at = now()
+    FROM users
+    WHERE wallets.user_id = users.id AND users.id = $1 AND users.is_deleted = true
+    AND wallets.deleted_at = users.deleted_at AND wallets.status <> 'closed'
+    RETURNING wallets.id
+)
+SELECT id, name, email, created_at, updated_at, deleted_at, is_deleted, version FROM restored_user
+`
+
+type RestoreUserRow struct {
+	ID        uuid.UUID          `json:"id"`
+	Name      string             `json:"name"`
+	Email     string             `json:"email"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+	IsDeleted *bool              `json:"is_deleted"`
+	Version   int64              `json:"version"`
+}
+
+func (q *Queries) RestoreUser(ctx context.Context, id uuid.UUID) (RestoreUserRow, error) {
+	row := q.db.QueryRow(ctx, restoreUser, id)
+	var i RestoreUserRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.IsDeleted,
+		&i.Version,
+	)
+	return i, err
+}
+
+const softDeleteUser = `-- name: SoftDeleteUser :one
+WITH locked_wallets AS (
+    SELECT wallets.id, wallets.balance, wallets.status FROM wallets
+    WHERE wallets.user_id = $1 AND wallets.is_deleted = false
+    FOR UPDATE
+), deleted_user AS (
+    UPDATE users
+    SET is_deleted = true, deleted_at = now(), updated_at = now(), version = version + 1
+    WHERE users.id = $1 AND users.is_deleted = false
+    AND NOT EXISTS (
+        SELECT 1 FROM locked_wallets
+        WHERE locked_wallets.balance <> 0 OR locked_wallets.status = 'frozen'
+    )
+    RETURNING id, name, email, created_at, updated_at, deleted_at, is_deleted, version
+), deleted_wallets AS (
+    UPDATE wallets
+    SET is_deleted = true, deleted_at = now(), updated_at = now()
+    WHERE wallets.user_id IN (SELECT id FROM deleted_user) AND wallets.is_deleted = false
+    RETURNING wallets.id
+)
+SELECT id, name, email, created_at, updated_at, deleted_at, is_deleted, version FROM deleted_user
+`
+
+type SoftDeleteUserRow struct {
+	ID        uuid.UUID          `json:"id"`
+	Name      string             `json:"name"`
+	Email     string             `json:"email"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+	IsDeleted *bool              `json:"is_deleted"`
+	Version   int64              `json:"version"`
+}
+
+// The wallets are locked before their balances and statuses are checked
+// so no credit or freeze can land between the check and the delete.
+// Frozen wallets are kept, as SoftDeleteWallet keeps them.
+func (q *Queries) SoftDeleteUser(ctx context.Context, userID uuid.UUID) (SoftDeleteUserRow, error) {
+	row := q.db.QueryRow(ctx, softDeleteUser, userID)
+	var i SoftDeleteUserRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.IsDeleted,
+		&i.Version,
+	)
+	return i, err
+}
+
+const updateUser = `-- name: UpdateUser :one
+UPDATE users
+SET name = COALESCE($1, name),
+    email = COALESCE($2, email),
+    updated_at = now(),
+    version = version + 1
+WHERE id = $3 AND is_deleted = false
+AND ($4::bigint IS NULL OR version = $4::bigint)
+RETURNING id, name, email, created_at, updated_at, deleted_at, is_deleted, version
+`
+
+type UpdateUserParams struct {
+	Name            *string   `json:"name"`
+	Email           *string   `json:"email"`
+	ID              uuid.UUID `json:"id"`
+	ExpectedVersion *int64    `json:"expected_version"`
+}
+
+func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUser,
+		arg.Name,
+		arg.Email,
+		arg.ID,
+		arg.ExpectedVersion,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.IsDeleted,
+		&i.Version,
 	)
 	return i, err
 }
+
+const userHasFrozenWallet = `-- name: UserHasFrozenWallet :one
+SELECT EXISTS (
+    SELECT 1 FROM wallets
+    WHERE wallets.user_id = $1 AND wallets.is_deleted = false AND wallets.status = 'frozen'
+)
+`
+
+func (q *Queries) UserHasFrozenWallet(ctx context.Context, userID uuid.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, userHasFrozenWallet, userID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
@@ -9,21 +9,23 @@ import (
 	"context"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 )
 
 const createWallet = `-- name: CreateWallet :one
 INSERT INTO wallets(
 user_id,
 balance
-) VALUES (
-    $1, $2
-) RETURNING id, user_id, balance, created_at, updated_at, deleted_at, is_deleted
+)
+SELECT $1::uuid, $2::numeric
+WHERE EXISTS (
+    SELECT 1 FROM users WHERE users.id = $1::uuid AND users.is_deleted = false
+) RETURNING id, user_id, balance, created_at, updated_at, deleted_at, is_deleted, status, status_reason
 `
 
 type CreateWalletParams struct {
-	UserID  uuid.UUID      `json:"user_id"`
-	Balance pgtype.Numeric `json:"balance"`
+	UserID  uuid.UUID       `json:"user_id"`
+	Balance decimal.Decimal `json:"balance"`
 }
 
 func (q *Queries) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wallet, error) {
@@ -37,6 +39,174 @@ func (q *Queries) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wal
 		&i.UpdatedAt,
 		&i.DeletedAt,
 		&i.IsDeleted,
+		&i.Status,
+		&i.StatusReason,
+	)
+	return i, err
+}
+
+const getWallet = `-- name: GetWallet :one
+SELECT id, user_id, balance, created_at, updated_at, deleted_at, is_deleted, status, status_reason FROM wallets
+WHERE id = $1 AND is_deleted = false
+`
+
+func (q *Queries) GetWallet(ctx context.Context, id uuid.UUID) (Wallet, error) {
+	row := q.db.QueryRow(ctx, getWallet, id)
+	var i Wallet
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Balance,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.IsDeleted,
+		&i.Status,
+		&i.StatusReason,
 	)
 	return i, err
 }
+
+const getWalletIncludingDeleted = `-- name: GetWalletIncludingDeleted :one
+SELECT id, user_id, balance, created_at, updated_at, deleted_at, is_deleted, status, status_reason FROM wallets
+WHERE id = $1
+`
+
+func (q *Queries) GetWalletIncludingDeleted(ctx context.Context, id uuid.UUID) (Wallet, error) {
+	row := q.db.QueryRow(ctx, getWalletIncludingDeleted, id)
+	var i Wallet
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Balance,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.IsDeleted,
+		&i.Status,
+		&i.StatusReason,
+	)
+	return i, err
+}
+
+const restoreWallet = `-- name: RestoreWallet :one
+UPDATE wallets
+SET is_deleted = false, deleted_at = NULL, updated_at = now()
+WHERE id = $1 AND is_deleted = true AND status <> 'closed'
+RETURNING id, user_id, balance, created_at, updated_at, deleted_at, is_deleted, status, status_reason
+`
+
+// Closing a wallet is final, deleting it does not undo that.
+func (q *Queries) RestoreWallet(ctx context.Context, id uuid.UUID) (Wallet, error) {
+	row := q.db.QueryRow(ctx, restoreWallet, id)
+	var i Wallet
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Balance,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.IsDeleted,
+		&i.Status,
+		&i.StatusReason,
+	)
+	return i, err
+}
+
+const softDeleteWallet = `-- name: SoftDeleteWallet :one
+UPDATE wallets
+SET is_deleted = true, deleted_at = now(), updated_at = now()
+WHERE id = $1 AND is_deleted = false AND COALESCE(balance, 0) = 0
+AND status <> 'frozen'
+RETURNING id, user_id, balance, created_at, updated_at, deleted_at, is_deleted, status, status_reason
+`
+
+// Frozen wallets are kept until the review that froze them is over.
+func (q *Queries) SoftDeleteWallet(ctx context.Context, id uuid.UUID) (Wallet, error) {
+	row := q.db.QueryRow(ctx, softDeleteWallet, id)
+	var i Wallet
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Balance,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.IsDeleted,
+		&i.Status,
+		&i.StatusReason,
+	)
+	return i, err
+}
+
+const updateWalletStatus = `-- name: UpdateWalletStatus :one
+UPDATE wallets
+SET status = $1, status_reason = $2, updated_at = now()
+WHERE id = $3 AND is_deleted = false AND status = $4
+AND ($1::text <> 'closed' OR COALESCE(balance, 0) = 0)
+RETURNING id, user_id, balance, created_at, updated_at, deleted_at, is_deleted, status, status_reason
+`
+
+type UpdateWalletStatusParams struct {
+	Status     string    `json:"status"`
+	Reason     *string   `json:"reason"`
+	ID         uuid.UUID `json:"id"`
+	FromStatus string    `json:"from_status"`
+}
+
+func (q *Queries) UpdateWalletStatus(ctx context.Context, arg UpdateWalletStatusParams) (Wallet, error) {
+	row := q.db.QueryRow(ctx, updateWalletStatus,
+		arg.Status,
+		arg.Reason,
+		arg.ID,
+		arg.FromStatus,
+	)
+	var i Wallet
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Balance,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.IsDeleted,
+		&i.Status,
+		&i.StatusReason,
+	)
+	return i, err
+}
+
+const walletBalancesByStatus = `-- name: WalletBalancesByStatus :many
+SELECT status, COUNT(*) AS wallets, COALESCE(SUM(balance), 0)::numeric AS balance
+FROM wallets
+WHERE is_deleted = false
+GROUP BY status
+`
+
+type WalletBalancesByStatusRow struct {
+	Status  string          `json:"status"`
+	Wallets int64           `json:"wallets"`
+	Balance decimal.Decimal `json:"balance"`
+}
+
+// Count and total balance of the wallets that are not deleted.
+func (q *Queries) WalletBalancesByStatus(ctx context.Context) ([]WalletBalancesByStatusRow, error) {
+	rows, err := q.db.Query(ctx, walletBalancesByStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WalletBalancesByStatusRow{}
+	for rows.Next() {
+		var i WalletBalancesByStatusRow
+		if err := rows.Scan(&i.Status, &i.Wallets, &i.Balance); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/Oloruntobi1/grey/pkg/redact"
+)
+
+// ArgsMode controls how query arguments are recorded on spans.
+type ArgsMode string
+
+const (
+	// ArgsOff records no arguments.
+	ArgsOff ArgsMode = "off"
+	// ArgsRedacted records the type of each argument and whether it
+	// is nil, never the value.
+	ArgsRedacted ArgsMode = "redacted"
+	// ArgsRaw records the values with the redaction policy applied,
+	// for local debugging only as fields it does not know of are kept.
+	ArgsRaw ArgsMode = "raw"
+)
+
+func (m ArgsMode) format(args []any, policy *redact.Policy) []string {
+	if m == ArgsOff || m == "" || len(args) == 0 {
+		return nil
+	}
+
+	formatted := make([]string, len(args))
+	for i, arg := range args {
+		if m == ArgsRaw {
+			formatted[i] = policy.Text(fmt.Sprintf("%v", arg))
+			continue
+		}
+		formatted[i] = redactArg(arg)
+	}
+	return formatted
+}
+
+func redactArg(arg any) string {
+	if arg == nil {
+		return "<nil>"
+	}
+	v := reflect.ValueOf(arg)
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return "<nil " + v.Type().String() + ">"
+	}
+	return "<" + v.Type().String() + ">"
+}
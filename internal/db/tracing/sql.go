@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"regexp"
+	"strings"
+)
+
+// statement is what the tracer learns from the SQL text alone.
+type statement struct {
+	// name is the sqlc query name, empty for other statements.
+	name string
+	// operation is the leading SQL keyword such as SELECT.
+	operation string
+	// table is the first table the statement reads or writes.
+	table string
+}
+
+var tableRef = regexp.MustCompile(`(?i)\b(from|into|update|join)\s+([a-z_][\w.]*)`)
+
+func parseStatement(sql string) statement {
+	var s statement
+
+	// sqlc starts every query with a -- name: <Name> :<kind> line
+	body := sql
+	for {
+		line, rest, found := strings.Cut(strings.TrimSpace(body), "\n")
+		if !strings.HasPrefix(line, "--") {
+			break
+		}
+		if fields := strings.Fields(line); s.name == "" && len(fields) >= 3 && fields[1] == "name:" {
+			s.name = fields[2]
+		}
+		if !found {
+			body = ""
+			break
+		}
+		body = rest
+	}
+
+	if fields := strings.Fields(body); len(fields) > 0 {
+		s.operation = strings.ToUpper(strings.TrimRight(fields[0], "(;"))
+	}
+
+	for _, m := range tableRef.FindAllStringSubmatchIndex(body, -1) {
+		// FROM now() in EXTRACT(EPOCH FROM now()) is a function call,
+		// while INSERT INTO users(name) lists columns
+		keyword := strings.ToLower(body[m[2]:m[3]])
+		if keyword != "into" && strings.HasPrefix(body[m[1]:], "(") {
+			continue
+		}
+		s.table = body[m[4]:m[5]]
+		break
+	}
+
+	return s
+}
+
+// spanName is the sqlc query name, or the operation and table
+// for statements sqlc did not generate.
+func (s statement) spanName() string {
+	switch {
+	case s.name != "":
+		return s.name
+	case s.operation != "" && s.table != "":
+		return s.operation + " " + s.table
+	case s.operation != "":
+		return s.operation
+	default:
+		return "query"
+	}
+}
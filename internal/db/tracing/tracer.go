@@ -0,0 +1,241 @@
+// Package tracing traces and measures everything pgx does on a
+// connection: queries, batches, copies, prepares and connects.
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/Oloruntobi1/grey/pkg/redact"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer implements the pgx tracer interfaces. Set it as the Tracer
+// of a pgx.ConnConfig, pgxpool.Config.ConnConfig included.
+type Tracer struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	args     ArgsMode
+	policy   *redact.Policy
+}
+
+var (
+	_ pgx.QueryTracer    = (*Tracer)(nil)
+	_ pgx.BatchTracer    = (*Tracer)(nil)
+	_ pgx.CopyFromTracer = (*Tracer)(nil)
+	_ pgx.PrepareTracer  = (*Tracer)(nil)
+	_ pgx.ConnectTracer  = (*Tracer)(nil)
+)
+
+// New returns a Tracer recording query arguments as args says. Raw
+// arguments and errors are redacted with the policy.
+func New(args ArgsMode, policy *redact.Policy) (*Tracer, error) {
+	duration, err := otel.Meter("pgx").Float64Histogram(
+		"db.client.operation.duration",
+		metric.WithDescription("Duration of database queries."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tracer{
+		tracer:   otel.Tracer("pgx"),
+		duration: duration,
+		args:     args,
+		policy:   policy,
+	}, nil
+}
+
+type queryKey struct{}
+
+// query is carried from the start to the end of a query
+// so its duration can be recorded with the same attributes.
+type query struct {
+	start time.Time
+	attrs []attribute.KeyValue
+}
+
+func (t *Tracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	stmt := parseStatement(data.SQL)
+	attrs := stmt.attributes()
+
+	ctx, span := t.tracer.Start(ctx, stmt.spanName(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(connAttributes(connConfig(conn))...),
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(semconv.DBStatement(data.SQL)),
+	)
+	if args := t.args.format(data.Args, t.policy); args != nil {
+		span.SetAttributes(attribute.StringSlice("db.statement.parameters", args))
+	}
+
+	return context.WithValue(ctx, queryKey{}, query{start: time.Now(), attrs: attrs})
+}
+
+func (t *Tracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if q, ok := ctx.Value(queryKey{}).(query); ok {
+		t.duration.Record(ctx, time.Since(q.start).Seconds(), metric.WithAttributes(
+			append(q.attrs, attribute.Bool("error", data.Err != nil))...,
+		))
+	}
+
+	if data.Err != nil {
+		t.policy.RecordError(span, data.Err)
+		return
+	}
+	span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+}
+
+// TraceBatchStart starts one span for the whole batch, every
+// query in it is recorded as an event on that span.
+func (t *Tracer) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	size := 0
+	if data.Batch != nil {
+		size = data.Batch.Len()
+	}
+
+	ctx, _ = t.tracer.Start(ctx, "batch",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(connAttributes(connConfig(conn))...),
+		trace.WithAttributes(attribute.Int("db.batch.size", size)),
+	)
+	return ctx
+}
+
+func (t *Tracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	span := trace.SpanFromContext(ctx)
+
+	stmt := parseStatement(data.SQL)
+	attrs := append(stmt.attributes(), semconv.DBStatement(data.SQL))
+	if args := t.args.format(data.Args, t.policy); args != nil {
+		attrs = append(attrs, attribute.StringSlice("db.statement.parameters", args))
+	}
+	if data.Err != nil {
+		attrs = append(attrs, attribute.String("error", t.policy.Text(data.Err.Error())))
+	} else {
+		attrs = append(attrs, attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	}
+
+	span.AddEvent(stmt.spanName(), trace.WithAttributes(attrs...))
+}
+
+func (t *Tracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if data.Err != nil {
+		t.policy.RecordError(span, data.Err)
+	}
+}
+
+func (t *Tracer) TraceCopyFromStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	table := data.TableName.Sanitize()
+
+	ctx, _ = t.tracer.Start(ctx, "COPY "+table,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(connAttributes(connConfig(conn))...),
+		trace.WithAttributes(
+			semconv.DBOperation("COPY"),
+			semconv.DBSQLTable(table),
+			attribute.StringSlice("db.copy.columns", data.ColumnNames),
+		),
+	)
+	return ctx
+}
+
+func (t *Tracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if data.Err != nil {
+		t.policy.RecordError(span, data.Err)
+		return
+	}
+	span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+}
+
+func (t *Tracer) TracePrepareStart(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareStartData) context.Context {
+	stmt := parseStatement(data.SQL)
+
+	ctx, _ = t.tracer.Start(ctx, "prepare "+stmt.spanName(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(connAttributes(connConfig(conn))...),
+		trace.WithAttributes(stmt.attributes()...),
+		trace.WithAttributes(
+			semconv.DBStatement(data.SQL),
+			attribute.String("db.prepared_statement", data.Name),
+		),
+	)
+	return ctx
+}
+
+func (t *Tracer) TracePrepareEnd(ctx context.Context, _ *pgx.Conn, data pgx.TracePrepareEndData) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	span.SetAttributes(attribute.Bool("db.already_prepared", data.AlreadyPrepared))
+	if data.Err != nil {
+		t.policy.RecordError(span, data.Err)
+	}
+}
+
+func (t *Tracer) TraceConnectStart(ctx context.Context, data pgx.TraceConnectStartData) context.Context {
+	ctx, _ = t.tracer.Start(ctx, "connect",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(connAttributes(data.ConnConfig)...),
+	)
+	return ctx
+}
+
+func (t *Tracer) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndData) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if data.Err != nil {
+		t.policy.RecordError(span, data.Err)
+	}
+}
+
+func (s statement) attributes() []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, 3)
+	if s.name != "" {
+		attrs = append(attrs, attribute.String("db.query.name", s.name))
+	}
+	if s.operation != "" {
+		attrs = append(attrs, semconv.DBOperation(s.operation))
+	}
+	if s.table != "" {
+		attrs = append(attrs, semconv.DBSQLTable(s.table))
+	}
+	return attrs
+}
+
+func connAttributes(cfg *pgx.ConnConfig) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.DBSystemPostgreSQL}
+	if cfg == nil {
+		return attrs
+	}
+	return append(attrs,
+		semconv.DBName(cfg.Database),
+		semconv.DBUser(cfg.User),
+		semconv.ServerAddress(cfg.Host),
+		semconv.ServerPort(int(cfg.Port)),
+	)
+}
+
+func connConfig(conn *pgx.Conn) *pgx.ConnConfig {
+	if conn == nil {
+		return nil
+	}
+	return conn.Config()
+}
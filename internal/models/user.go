@@ -1,7 +1,19 @@
 package models
 
+import "time"
+
 type User struct {
-	ID    string `json:"-"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID        string    `json:"-"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Version   int64     `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UserUpdate holds the fields of a partial user update.
+// Nil fields are left unchanged.
+type UserUpdate struct {
+	Name  *string
+	Email *string
 }
@@ -1,11 +1,60 @@
 package models
 
 import (
+	"time"
+
 	"github.com/shopspring/decimal"
 )
 
 type Wallet struct {
-	ID      string          `json:"id"`
-	UserID  string          `json:"user_id"`
-	Balance decimal.Decimal `json:"balance"`
+	ID           string          `json:"id"`
+	UserID       string          `json:"user_id"`
+	Balance      decimal.Decimal `json:"balance"`
+	Status       WalletStatus    `json:"status"`
+	StatusReason string          `json:"status_reason,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// WalletStatus controls which money movements a wallet accepts.
+type WalletStatus string
+
+const (
+	WalletActive        WalletStatus = "active"
+	WalletFrozen        WalletStatus = "frozen"
+	WalletDebitBlocked  WalletStatus = "debit_blocked"
+	WalletCreditBlocked WalletStatus = "credit_blocked"
+	WalletClosed        WalletStatus = "closed"
+)
+
+// walletTransitions lists the statuses each status may move to.
+// Closed is terminal.
+var walletTransitions = map[WalletStatus][]WalletStatus{
+	WalletActive:        {WalletFrozen, WalletDebitBlocked, WalletCreditBlocked, WalletClosed},
+	WalletFrozen:        {WalletActive, WalletDebitBlocked, WalletCreditBlocked, WalletClosed},
+	WalletDebitBlocked:  {WalletActive, WalletFrozen, WalletClosed},
+	WalletCreditBlocked: {WalletActive, WalletFrozen, WalletClosed},
+}
+
+func (s WalletStatus) Valid() bool {
+	switch s {
+	case WalletActive, WalletFrozen, WalletDebitBlocked, WalletCreditBlocked, WalletClosed:
+		return true
+	}
+	return false
+}
+
+func (s WalletStatus) CanTransitionTo(next WalletStatus) bool {
+	for _, allowed := range walletTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// WalletBalance totals the wallets in one status.
+type WalletBalance struct {
+	Status  WalletStatus
+	Wallets int64
+	Balance decimal.Decimal
 }
@@ -0,0 +1,114 @@
+// Package ratelimit implements token bucket rate limits. Buckets live in
+// a Store so a single instance can keep them in memory while several
+// replicas share them through Postgres.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Rate requests per second on average
+// and up to Burst requests at once.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next token, zero when allowed.
+	RetryAfter time.Duration
+}
+
+type Store interface {
+	// Take takes one token from the bucket for key.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	// Sweep drops buckets that were not used for idle.
+	Sweep(ctx context.Context, idle time.Duration) error
+}
+
+// ParseLimit parses limits written as count/period[:burst], for example
+// 10/s, 100/m:20 or 5/30s. The burst defaults to the count.
+func ParseLimit(s string) (Limit, error) {
+	rate, burst, hasBurst := strings.Cut(strings.TrimSpace(s), ":")
+
+	count, period, found := strings.Cut(rate, "/")
+	if !found {
+		return Limit{}, fmt.Errorf("invalid limit %q: expected count/period[:burst]", s)
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q: count must be a positive integer", s)
+	}
+	// allow 10/s as well as 10/1s
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q: period must be a positive duration", s)
+	}
+
+	limit := Limit{Rate: float64(n) / d.Seconds(), Burst: n}
+	if hasBurst {
+		limit.Burst, err = strconv.Atoi(burst)
+		if err != nil || limit.Burst <= 0 {
+			return Limit{}, fmt.Errorf("invalid limit %q: burst must be a positive integer", s)
+		}
+	}
+	return limit, nil
+}
+
+// ParseLimits parses name=limit pairs separated by semicolons, such
+// as "POST /v1/users=5/m;GET /v1/users/{id}=50/s:100".
+func ParseLimits(s string) (map[string]Limit, error) {
+	limits := map[string]Limit{}
+	for _, pair := range strings.Split(s, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, spec, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid limit %q: expected name=limit", pair)
+		}
+		limit, err := ParseLimit(spec)
+		if err != nil {
+			return nil, err
+		}
+		limits[strings.TrimSpace(name)] = limit
+	}
+	return limits, nil
+}
+
+// refill returns the tokens of a bucket that had tokens elapsed ago.
+func refill(tokens float64, elapsed time.Duration, limit Limit) float64 {
+	return math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.Rate)
+}
+
+// newResult describes a bucket left with tokens after a take.
+func newResult(limit Limit, tokens float64, allowed bool) Result {
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     seconds((float64(limit.Burst) - tokens) / limit.Rate),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / limit.Rate)
+	}
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Max(0, s) * float64(time.Second))
+}
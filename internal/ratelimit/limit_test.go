@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{in: "10/s", want: Limit{Rate: 10, Burst: 10}},
+		{in: "10/1s", want: Limit{Rate: 10, Burst: 10}},
+		{in: "120/m:20", want: Limit{Rate: 2, Burst: 20}},
+		{in: "5/30s", want: Limit{Rate: 5.0 / 30, Burst: 5}},
+		{in: " 1/h ", want: Limit{Rate: 1.0 / 3600, Burst: 1}},
+		{in: "10", wantErr: true},
+		{in: "0/s", wantErr: true},
+		{in: "-1/s", wantErr: true},
+		{in: "x/s", wantErr: true},
+		{in: "10/", wantErr: true},
+		{in: "10/0s", wantErr: true},
+		{in: "10/fortnight", wantErr: true},
+		{in: "10/s:0", wantErr: true},
+		{in: "10/s:x", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLimit(%q) error = %v, want error %t", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	got, err := ParseLimits("POST /v1/users=5/m; GET /v1/users/{id}=50/s:100;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Limit{
+		"POST /v1/users":     {Rate: 5.0 / 60, Burst: 5},
+		"GET /v1/users/{id}": {Rate: 50, Burst: 100},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d limits, want %d", len(got), len(want))
+	}
+	for name, limit := range want {
+		if got[name] != limit {
+			t.Errorf("%s = %+v, want %+v", name, got[name], limit)
+		}
+	}
+
+	for _, in := range []string{"POST /v1/users", "POST /v1/users=often"} {
+		if _, err := ParseLimits(in); err == nil {
+			t.Errorf("ParseLimits(%q) succeeded", in)
+		}
+	}
+}
+
+func TestRefill(t *testing.T) {
+	limit := Limit{Rate: 2, Burst: 10}
+
+	tests := []struct {
+		tokens  float64
+		elapsed time.Duration
+		want    float64
+	}{
+		{0, 0, 0},
+		{0, time.Second, 2},
+		{0, 250 * time.Millisecond, 0.5},
+		{9, time.Second, 10},
+		{0, time.Hour, 10},
+	}
+	for _, tt := range tests {
+		if got := refill(tt.tokens, tt.elapsed, limit); got != tt.want {
+			t.Errorf("refill(%v, %s) = %v, want %v", tt.tokens, tt.elapsed, got, tt.want)
+		}
+	}
+}
+
+func TestNewResult(t *testing.T) {
+	limit := Limit{Rate: 2, Burst: 10}
+
+	tests := []struct {
+		name    string
+		tokens  float64
+		allowed bool
+		want    Result
+	}{
+		{
+			name:    "full after a take",
+			tokens:  9,
+			allowed: true,
+			want:    Result{Allowed: true, Limit: 10, Remaining: 9, Reset: 500 * time.Millisecond},
+		},
+		{
+			name:    "partial token left",
+			tokens:  2.5,
+			allowed: true,
+			want:    Result{Allowed: true, Limit: 10, Remaining: 2, Reset: 3750 * time.Millisecond},
+		},
+		{
+			name:    "empty",
+			tokens:  0,
+			allowed: false,
+			want:    Result{Allowed: false, Limit: 10, Remaining: 0, Reset: 5 * time.Second, RetryAfter: 500 * time.Millisecond},
+		},
+		{
+			name:    "almost a token",
+			tokens:  0.75,
+			allowed: false,
+			want:    Result{Allowed: false, Limit: 10, Remaining: 0, Reset: 4625 * time.Millisecond, RetryAfter: 125 * time.Millisecond},
+		},
+	}
+	for _, tt := range tests {
+		if got := newResult(limit, tt.tokens, tt.allowed); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
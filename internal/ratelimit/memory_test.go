@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	ctx := context.Background()
+	limit := Limit{Rate: 1, Burst: 3}
+
+	tests := []struct {
+		name string
+		// elapsed is how long ago the bucket was last
+		// used, the bucket is drained when it is set
+		elapsed time.Duration
+		takes   int
+		want    []bool
+	}{
+		{name: "new bucket allows the burst", takes: 4, want: []bool{true, true, true, false}},
+		{name: "refills one token a second", elapsed: time.Second, takes: 2, want: []bool{true, false}},
+		{name: "refills up to the burst", elapsed: time.Hour, takes: 4, want: []bool{true, true, true, false}},
+		{name: "no token before the refill", elapsed: 500 * time.Millisecond, takes: 1, want: []bool{false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryStore()
+			if tt.elapsed > 0 {
+				s.buckets["k"] = &bucket{tokens: 0, updated: time.Now().Add(-tt.elapsed)}
+			}
+			for i := 0; i < tt.takes; i++ {
+				result, err := s.Take(ctx, "k", limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.Allowed != tt.want[i] {
+					t.Fatalf("take %d: allowed = %t, want %t", i, result.Allowed, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStoreRetryAfter(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	limit := Limit{Rate: 0.5, Burst: 1}
+
+	if result, _ := s.Take(ctx, "k", limit); !result.Allowed || result.RetryAfter != 0 {
+		t.Fatalf("first take: %+v", result)
+	}
+	result, _ := s.Take(ctx, "k", limit)
+	if result.Allowed {
+		t.Fatal("second take was allowed")
+	}
+	// one token takes two seconds at half a token a second
+	if result.RetryAfter <= 1900*time.Millisecond || result.RetryAfter > 2*time.Second {
+		t.Errorf("RetryAfter = %s, want about 2s", result.RetryAfter)
+	}
+	if result.Remaining != 0 || result.Limit != 1 {
+		t.Errorf("got %+v", result)
+	}
+}
+
+func TestMemoryStoreKeysAreSeparate(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	limit := Limit{Rate: 1, Burst: 1}
+
+	for _, key := range []string{"POST /v1/users|ip:a", "POST /v1/wallets|ip:a", "POST /v1/users|ip:b"} {
+		if result, _ := s.Take(ctx, key, limit); !result.Allowed {
+			t.Errorf("%s shares a bucket", key)
+		}
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	s.buckets["old"] = &bucket{updated: time.Now().Add(-time.Hour)}
+	s.buckets["new"] = &bucket{updated: time.Now()}
+
+	if err := s.Sweep(ctx, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.buckets["old"]; ok {
+		t.Error("the idle bucket was kept")
+	}
+	if _, ok := s.buckets["new"]; !ok {
+		t.Error("the used bucket was dropped")
+	}
+}
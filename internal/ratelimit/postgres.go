@@ -0,0 +1,41 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/Oloruntobi1/grey/internal/db/sqlc"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so every
+// replica enforces the same limits. Each take is a single upsert and
+// uses the database clock, so replicas need not agree on the time.
+type PostgresStore struct {
+	db db.Querier
+}
+
+func NewPostgresStore(db db.Querier) *PostgresStore {
+	return &PostgresStore{
+		db: db,
+	}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	row, err := s.db.TakeRateLimitToken(ctx, db.TakeRateLimitTokenParams{
+		Key:   key,
+		Burst: float64(limit.Burst),
+		Rate:  limit.Rate,
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	return newResult(limit, row.Tokens, row.Allowed), nil
+}
+
+func (s *PostgresStore) Sweep(ctx context.Context, idle time.Duration) error {
+	if _, err := s.db.DeleteIdleRateLimitBuckets(ctx, idle.Seconds()); err != nil {
+		return fmt.Errorf("failed to sweep rate limit buckets: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// IdleBucketTTL is how long an unused bucket is kept. Buckets that
+// refill within it are full by then, so dropping them changes nothing.
+const IdleBucketTTL = time.Hour
+
+// RunSweeper drops idle buckets from store every interval until ctx is done.
+func RunSweeper(ctx context.Context, store Store, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := store.Sweep(ctx, IdleBucketTTL); err != nil {
+				logger.ErrorContext(
+					ctx,
+					"failed_to_sweep_rate_limit_buckets",
+					slog.Any("err", err),
+				)
+			}
+		}
+	}
+}
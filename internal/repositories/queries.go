@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	db "github.com/Oloruntobi1/grey/internal/db/sqlc"
+)
+
+// Queries hands out the queries a repository runs: Primary for writes
+// and the reads deciding them, Reader for reads that may be slightly
+// stale. replica.Router is the implementation used by the app.
+type Queries interface {
+	Primary(ctx context.Context) db.Querier
+	Reader(ctx context.Context) db.Querier
+}
@@ -7,23 +7,28 @@ import (
 
 	db "github.com/Oloruntobi1/grey/internal/db/sqlc"
 	"github.com/Oloruntobi1/grey/internal/models"
+	"github.com/Oloruntobi1/grey/pkg/redact"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 )
 
 var (
 	ErrUserNotFound      = errors.New("user not found")
 	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrUserHasBalance    = errors.New("user has wallets with a non-zero balance")
+	ErrUserNotDeleted    = errors.New("user is not deleted")
+	ErrEmailAlreadyInUse = errors.New("email already in use")
+	ErrVersionMismatch   = errors.New("resource was modified by another request")
 )
 
 type UserRepository struct {
-	db     db.Querier
+	db     Queries
 	tracer trace.Tracer
 }
 
-func NewUserRepository(db db.Querier) *UserRepository {
+func NewUserRepository(db Queries) *UserRepository {
 	return &UserRepository{
 		db:     db,
 		tracer: otel.Tracer("userRepository"),
@@ -36,29 +41,184 @@ func (r *UserRepository) CreateUser(ctx context.Context, userModel *models.User)
 
 	dbUser, err := r.toDb(userModel)
 	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
+		redact.RecordError(span, err)
 		return "", fmt.Errorf("mapping failed: err %v", err)
 	}
 
-	userDB, err := r.db.CreateUser(ctx, dbUser)
+	userDB, err := r.db.Primary(ctx).CreateUser(ctx, dbUser)
 	if err != nil {
 		errCode := db.ErrorCode(err)
 		if errCode == db.UniqueViolation {
 			err = ErrUserAlreadyExists
-			span.SetStatus(codes.Error, err.Error())
-			span.RecordError(err, trace.WithAttributes(attribute.String("email", userModel.Email)))
+			redact.RecordError(span, err, attribute.String("email", userModel.Email))
 			return "", err
 		}
 		err = fmt.Errorf("failed to add user in db: %w", err)
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
+		redact.RecordError(span, err)
 		return "", err
 	}
 
 	return userDB.ID.String(), nil
 }
 
+// DeleteUser soft deletes a user together with its wallets. It
+// refuses while any of the user's wallets is frozen or holds a balance.
+func (r *UserRepository) DeleteUser(ctx context.Context, id string) error {
+	ctx, span := r.tracer.Start(ctx, "userRepo.Delete")
+	defer span.End()
+
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	_, err = r.db.Primary(ctx).SoftDeleteUser(ctx, userID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, db.ErrRecordNotFound) {
+		err = fmt.Errorf("failed to delete user in db: %w", err)
+		redact.RecordError(span, err)
+		return err
+	}
+
+	// Nothing was deleted, either the user does not exist or
+	// one of its wallets is frozen or still holds money.
+	_, err = r.db.Primary(ctx).GetUser(ctx, userID)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to get user from db: %w", err)
+		redact.RecordError(span, err)
+		return err
+	}
+
+	frozen, err := r.db.Primary(ctx).UserHasFrozenWallet(ctx, userID)
+	if err != nil {
+		err = fmt.Errorf("failed to check user wallets in db: %w", err)
+		redact.RecordError(span, err)
+		return err
+	}
+	if frozen {
+		return ErrWalletFrozen
+	}
+	return ErrUserHasBalance
+}
+
+// RestoreUser undoes a soft delete, restoring the wallets
+// that were deleted along with the user.
+func (r *UserRepository) RestoreUser(ctx context.Context, id string) error {
+	ctx, span := r.tracer.Start(ctx, "userRepo.Restore")
+	defer span.End()
+
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	_, err = r.db.Primary(ctx).RestoreUser(ctx, userID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, db.ErrRecordNotFound) {
+		err = fmt.Errorf("failed to restore user in db: %w", err)
+		redact.RecordError(span, err)
+		return err
+	}
+
+	_, err = r.db.Primary(ctx).GetUserIncludingDeleted(ctx, userID)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to get user from db: %w", err)
+		redact.RecordError(span, err)
+		return err
+	}
+
+	return ErrUserNotDeleted
+}
+
+func (r *UserRepository) GetUser(ctx context.Context, id string) (*models.User, error) {
+	ctx, span := r.tracer.Start(ctx, "userRepo.Get")
+	defer span.End()
+
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	userDB, err := r.db.Reader(ctx).GetUser(ctx, userID)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to get user from db: %w", err)
+		redact.RecordError(span, err)
+		return nil, err
+	}
+
+	return r.fromDb(userDB), nil
+}
+
+// UpdateUser applies a partial update. When expectedVersion is set the
+// update only happens if the stored version still matches it.
+func (r *UserRepository) UpdateUser(ctx context.Context, id string, update *models.UserUpdate, expectedVersion *int64) (*models.User, error) {
+	ctx, span := r.tracer.Start(ctx, "userRepo.Update")
+	defer span.End()
+
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	userDB, err := r.db.Primary(ctx).UpdateUser(ctx, db.UpdateUserParams{
+		ID:              userID,
+		Name:            update.Name,
+		Email:           update.Email,
+		ExpectedVersion: expectedVersion,
+	})
+	if err == nil {
+		return r.fromDb(userDB), nil
+	}
+	if db.ErrorCode(err) == db.UniqueViolation {
+		err = ErrEmailAlreadyInUse
+		redact.RecordError(span, err)
+		return nil, err
+	}
+	if !errors.Is(err, db.ErrRecordNotFound) {
+		err = fmt.Errorf("failed to update user in db: %w", err)
+		redact.RecordError(span, err)
+		return nil, err
+	}
+
+	// Nothing was updated, either the user does not
+	// exist or it changed since the caller read it.
+	_, err = r.db.Primary(ctx).GetUser(ctx, userID)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to get user from db: %w", err)
+		redact.RecordError(span, err)
+		return nil, err
+	}
+
+	return nil, ErrVersionMismatch
+}
+
+func (u *UserRepository) fromDb(userDB db.User) *models.User {
+	return &models.User{
+		ID:        userDB.ID.String(),
+		Name:      userDB.Name,
+		Email:     userDB.Email,
+		Version:   userDB.Version,
+		CreatedAt: userDB.CreatedAt.Time,
+		UpdatedAt: userDB.UpdatedAt.Time,
+	}
+}
+
 func (u *UserRepository) toDb(userModel *models.User) (db.CreateUserParams, error) {
 	user := db.CreateUserParams{
 		Name:  userModel.Name,
@@ -7,21 +7,45 @@ import (
 
 	db "github.com/Oloruntobi1/grey/internal/db/sqlc"
 	"github.com/Oloruntobi1/grey/internal/models"
+	"github.com/Oloruntobi1/grey/pkg/redact"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 )
 
-var ErrWalletNotFound = errors.New("wallet not found")
+var (
+	ErrWalletNotFound     = errors.New("wallet not found")
+	ErrWalletHasBalance   = errors.New("wallet has a non-zero balance")
+	ErrWalletNotDeleted   = errors.New("wallet is not deleted")
+	ErrWalletOwnerDeleted = errors.New("wallet owner is deleted")
+	ErrWalletStatusStale  = errors.New("wallet changed while updating its status")
+	ErrWalletFrozen       = errors.New("wallet is frozen")
+	ErrWalletClosed       = errors.New("wallet is closed")
+	// ErrWalletDebitBlocked and ErrWalletCreditBlocked are returned
+	// when the wallet status refuses the balance change.
+	ErrWalletDebitBlocked  = errors.New("wallet does not allow debits")
+	ErrWalletCreditBlocked = errors.New("wallet does not allow credits")
+)
+
+// walletStatusError returns the domain error for the SQLSTATE raised
+// by the wallet status guard, or err when it is not one of them.
+func walletStatusError(err error) error {
+	switch db.ErrorCode(err) {
+	case db.WalletDebitBlocked:
+		return ErrWalletDebitBlocked
+	case db.WalletCreditBlocked:
+		return ErrWalletCreditBlocked
+	}
+	return err
+}
 
 type WalletRepository struct {
-	db     db.Querier
+	db     Queries
 	tracer trace.Tracer
 }
 
-func NewWalletRepository(db db.Querier) *WalletRepository {
+func NewWalletRepository(db Queries) *WalletRepository {
 	return &WalletRepository{
 		db:     db,
 		tracer: otel.Tracer("walletRepository"),
@@ -34,30 +58,208 @@ func (r *WalletRepository) CreateWallet(ctx context.Context, walletModel *models
 
 	dbWallet, err := r.toDb(walletModel)
 	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
+		redact.RecordError(span, err)
 		return "", fmt.Errorf("mapping failed: err %v", err)
 	}
 
-	walletDB, err := r.db.CreateWallet(ctx, dbWallet)
+	walletDB, err := r.db.Primary(ctx).CreateWallet(ctx, dbWallet)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		// the insert only happens for users that are not deleted
+		return "", ErrUserNotFound
+	}
 	if err != nil {
-		err = fmt.Errorf("failed to add wallet in db: %w", err)
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
+		err = fmt.Errorf("failed to add wallet in db: %w", walletStatusError(err))
+		redact.RecordError(span, err)
 		return "", err
 	}
 
 	return walletDB.ID.String(), nil
 }
 
+// DeleteWallet soft deletes a wallet that holds no balance
+// and is not frozen.
+func (r *WalletRepository) DeleteWallet(ctx context.Context, id string) error {
+	ctx, span := r.tracer.Start(ctx, "walletRepo.Delete")
+	defer span.End()
+
+	walletID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrWalletNotFound
+	}
+
+	_, err = r.db.Primary(ctx).SoftDeleteWallet(ctx, walletID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, db.ErrRecordNotFound) {
+		err = fmt.Errorf("failed to delete wallet in db: %w", walletStatusError(err))
+		redact.RecordError(span, err)
+		return err
+	}
+
+	walletDB, err := r.db.Primary(ctx).GetWallet(ctx, walletID)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return ErrWalletNotFound
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to get wallet from db: %w", err)
+		redact.RecordError(span, err)
+		return err
+	}
+
+	if models.WalletStatus(walletDB.Status) == models.WalletFrozen {
+		return ErrWalletFrozen
+	}
+	return ErrWalletHasBalance
+}
+
+// RestoreWallet undoes a soft delete. Wallets of deleted users must
+// be restored through the user and closed wallets stay deleted.
+func (r *WalletRepository) RestoreWallet(ctx context.Context, id string) error {
+	ctx, span := r.tracer.Start(ctx, "walletRepo.Restore")
+	defer span.End()
+
+	walletID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrWalletNotFound
+	}
+
+	walletDB, err := r.db.Primary(ctx).GetWalletIncludingDeleted(ctx, walletID)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return ErrWalletNotFound
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to get wallet from db: %w", err)
+		redact.RecordError(span, err)
+		return err
+	}
+	if models.WalletStatus(walletDB.Status) == models.WalletClosed {
+		return ErrWalletClosed
+	}
+
+	_, err = r.db.Primary(ctx).GetUser(ctx, walletDB.UserID)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return ErrWalletOwnerDeleted
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to get wallet owner from db: %w", err)
+		redact.RecordError(span, err)
+		return err
+	}
+
+	_, err = r.db.Primary(ctx).RestoreWallet(ctx, walletID)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return ErrWalletNotDeleted
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to restore wallet in db: %w", walletStatusError(err))
+		redact.RecordError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *WalletRepository) GetWallet(ctx context.Context, id string) (*models.Wallet, error) {
+	ctx, span := r.tracer.Start(ctx, "walletRepo.Get")
+	defer span.End()
+
+	walletID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+
+	walletDB, err := r.db.Reader(ctx).GetWallet(ctx, walletID)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return nil, ErrWalletNotFound
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to get wallet from db: %w", err)
+		redact.RecordError(span, err)
+		return nil, err
+	}
+
+	return r.fromDb(walletDB), nil
+}
+
+// UpdateWalletStatus moves a wallet from one status to another. It fails
+// with ErrWalletStatusStale if the wallet is no longer in the from status,
+// or holds a balance when being closed.
+func (r *WalletRepository) UpdateWalletStatus(ctx context.Context, id string, from, to models.WalletStatus, reason string) (*models.Wallet, error) {
+	ctx, span := r.tracer.Start(ctx, "walletRepo.UpdateStatus")
+	defer span.End()
+
+	walletID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+
+	walletDB, err := r.db.Primary(ctx).UpdateWalletStatus(ctx, db.UpdateWalletStatusParams{
+		ID:         walletID,
+		FromStatus: string(from),
+		Status:     string(to),
+		Reason:     &reason,
+	})
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return nil, ErrWalletStatusStale
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to update wallet status in db: %w", walletStatusError(err))
+		redact.RecordError(span, err)
+		return nil, err
+	}
+
+	return r.fromDb(walletDB), nil
+}
+
+// WalletBalances totals the wallets that are not deleted by status.
+func (r *WalletRepository) WalletBalances(ctx context.Context) ([]models.WalletBalance, error) {
+	ctx, span := r.tracer.Start(ctx, "walletRepo.Balances")
+	defer span.End()
+
+	rows, err := r.db.Reader(ctx).WalletBalancesByStatus(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to get wallet balances from db: %w", err)
+		redact.RecordError(span, err)
+		return nil, err
+	}
+
+	balances := make([]models.WalletBalance, len(rows))
+	for i, row := range rows {
+		balances[i] = models.WalletBalance{
+			Status:  models.WalletStatus(row.Status),
+			Wallets: row.Wallets,
+			Balance: row.Balance,
+		}
+	}
+	return balances, nil
+}
+
+func (u *WalletRepository) fromDb(walletDB db.Wallet) *models.Wallet {
+	wallet := &models.Wallet{
+		ID:        walletDB.ID.String(),
+		UserID:    walletDB.UserID.String(),
+		Status:    models.WalletStatus(walletDB.Status),
+		CreatedAt: walletDB.CreatedAt.Time,
+	}
+	if walletDB.Balance.Valid {
+		wallet.Balance = decimal.NewFromBigInt(walletDB.Balance.Int, walletDB.Balance.Exp)
+	}
+	if walletDB.StatusReason != nil {
+		wallet.StatusReason = *walletDB.StatusReason
+	}
+	return wallet
+}
+
 func (u *WalletRepository) toDb(walletModel *models.Wallet) (db.CreateWalletParams, error) {
+	userID, err := uuid.Parse(walletModel.UserID)
+	if err != nil {
+		return db.CreateWalletParams{}, fmt.Errorf("invalid user id: %w", err)
+	}
+
 	wallet := db.CreateWalletParams{
-		UserID: uuid.MustParse(walletModel.UserID),
-		Balance: pgtype.Numeric{
-			Int:   walletModel.Balance.BigInt(),
-			Exp:   walletModel.Balance.Exponent(),
-			Valid: true,
-		},
+		UserID:  userID,
+		Balance: walletModel.Balance,
 	}
 
 	return wallet, nil
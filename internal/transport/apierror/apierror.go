@@ -0,0 +1,72 @@
+// Package apierror is the one table tying domain errors to what the
+// HTTP and gRPC transports answer with, so both report them alike.
+// It also writes the HTTP Error envelope for every part of the HTTP
+// server that rejects requests.
+package apierror
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Oloruntobi1/grey/internal/auth"
+	"github.com/Oloruntobi1/grey/internal/repositories"
+	"github.com/Oloruntobi1/grey/internal/transport/http/domains/wallets"
+	"google.golang.org/grpc/codes"
+)
+
+// Mapping ties a domain error to the HTTP status and gRPC code returned
+// for it. Reason is the code of the HTTP Error envelope and the reason of
+// the gRPC ErrorInfo detail, so REST clients going through the gateway
+// see the same one.
+type Mapping struct {
+	Err        error
+	HTTPStatus int
+	GRPCCode   codes.Code
+	Reason     string
+}
+
+var mappings = []Mapping{
+	{auth.ErrUnauthenticated, http.StatusUnauthorized, codes.Unauthenticated, "unauthenticated"},
+	{auth.ErrPermissionDenied, http.StatusForbidden, codes.PermissionDenied, "permission_denied"},
+	{repositories.ErrUserNotFound, http.StatusNotFound, codes.NotFound, "user_not_found"},
+	{repositories.ErrUserAlreadyExists, http.StatusConflict, codes.AlreadyExists, "user_already_exists"},
+	{repositories.ErrUserHasBalance, http.StatusConflict, codes.FailedPrecondition, "user_has_balance"},
+	{repositories.ErrUserNotDeleted, http.StatusConflict, codes.FailedPrecondition, "user_not_deleted"},
+	{repositories.ErrEmailAlreadyInUse, http.StatusConflict, codes.AlreadyExists, "email_already_in_use"},
+	{repositories.ErrVersionMismatch, http.StatusPreconditionFailed, codes.Aborted, "version_mismatch"},
+	{repositories.ErrWalletNotFound, http.StatusNotFound, codes.NotFound, "wallet_not_found"},
+	{repositories.ErrWalletHasBalance, http.StatusConflict, codes.FailedPrecondition, "wallet_has_balance"},
+	{repositories.ErrWalletNotDeleted, http.StatusConflict, codes.FailedPrecondition, "wallet_not_deleted"},
+	{repositories.ErrWalletOwnerDeleted, http.StatusConflict, codes.FailedPrecondition, "wallet_owner_deleted"},
+	{repositories.ErrWalletStatusStale, http.StatusConflict, codes.Aborted, "wallet_status_stale"},
+	{repositories.ErrWalletFrozen, http.StatusConflict, codes.FailedPrecondition, "wallet_frozen"},
+	{repositories.ErrWalletClosed, http.StatusConflict, codes.FailedPrecondition, "wallet_closed"},
+	{repositories.ErrWalletDebitBlocked, http.StatusUnprocessableEntity, codes.FailedPrecondition, "wallet_debit_blocked"},
+	{repositories.ErrWalletCreditBlocked, http.StatusUnprocessableEntity, codes.FailedPrecondition, "wallet_credit_blocked"},
+	{wallets.ErrInvalidWalletStatus, http.StatusBadRequest, codes.InvalidArgument, "invalid_wallet_status"},
+	{wallets.ErrStatusReasonRequired, http.StatusBadRequest, codes.InvalidArgument, "status_reason_required"},
+	{wallets.ErrInvalidStatusTransition, http.StatusConflict, codes.FailedPrecondition, "invalid_status_transition"},
+}
+
+// Find returns the mapping of the first domain error in err's chain,
+// false when err is not a domain error.
+func Find(err error) (Mapping, bool) {
+	for _, m := range mappings {
+		if errors.Is(err, m.Err) {
+			return m, true
+		}
+	}
+	return Mapping{}, false
+}
+
+// FindReason returns the mapping with the given reason, the gateway
+// uses it to answer gRPC errors with the same HTTP status as the hand
+// written routes.
+func FindReason(reason string) (Mapping, bool) {
+	for _, m := range mappings {
+		if m.Reason == reason {
+			return m, true
+		}
+	}
+	return Mapping{}, false
+}
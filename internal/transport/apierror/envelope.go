@@ -0,0 +1,105 @@
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GenericMeta ...
+type GenericMeta struct {
+	TraceID *string `json:"trace_id,omitempty"`
+}
+
+func NewGenericMeta(ctx context.Context) *GenericMeta {
+	return &GenericMeta{
+		TraceID: Extract(ctx),
+	}
+}
+
+func String(v string) *string {
+	return &v
+}
+
+// ErrorField ...
+type ErrorField struct {
+	Name    *string `json:"name,omitempty"`
+	Message *string `json:"message,omitempty"`
+}
+
+// ErrorBase ...
+type ErrorBase struct {
+	Code    *string      `json:"code,omitempty"`
+	Message *string      `json:"message,omitempty"`
+	Fields  []ErrorField `json:"fields,omitempty"`
+}
+
+// Error ...
+type Error struct {
+	Error *ErrorBase   `json:"error,omitempty"`
+	Meta  *GenericMeta `json:"meta,omitempty"`
+}
+
+func ResponseWithError(ctx context.Context, err *ErrorBase) *Error {
+	return &Error{
+		Error: err,
+		Meta:  NewGenericMeta(ctx),
+	}
+}
+
+// WriteError answers with the Error envelope. The hand written routes,
+// the middleware and the gateway all write their errors with it, so
+// clients see one shape whichever of them rejects a request.
+func WriteError(ctx context.Context, w http.ResponseWriter, logger *slog.Logger, status int, code, message string, fields ...ErrorField) {
+	responseJSON, err := json.Marshal(ResponseWithError(ctx, &ErrorBase{
+		Code:    String(code),
+		Message: String(message),
+		Fields:  fields,
+	}))
+	if err != nil {
+		logger.ErrorContext(
+			ctx,
+			"failed_to_marshal_response",
+			slog.Any("err", err),
+		)
+		http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(responseJSON); err != nil {
+		logger.ErrorContext(
+			ctx,
+			"failed_to_write_response",
+			slog.Any("err", err),
+		)
+	}
+}
+
+func Extract(ctx context.Context) *string {
+	sc := trace.SpanFromContext(ctx).SpanContext()
+	var xRayTraceID string
+	if sc.TraceID().IsValid() {
+		xRayTraceID = XRayTraceID(sc.TraceID())
+	}
+
+	return String(xRayTraceID)
+}
+
+const FieldTraceID = "trace-id"
+
+const (
+	traceIDVersion         = "1"
+	traceIDDelimiter       = "-"
+	traceIDFirstPartLength = 8
+)
+
+func XRayTraceID(traceID trace.TraceID) string {
+	otTraceID := traceID.String()
+	return traceIDVersion + traceIDDelimiter + otTraceID[:traceIDFirstPartLength] +
+		traceIDDelimiter + otTraceID[traceIDFirstPartLength:]
+}
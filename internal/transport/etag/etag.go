@@ -0,0 +1,45 @@
+// Package etag turns user row versions into the strong ETags the REST
+// routes send and parses the If-Match headers checked against them, so
+// the hand written and the generated routes agree on both.
+package etag
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/Oloruntobi1/grey/internal/repositories"
+)
+
+var ErrInvalidIfMatch = errors.New("If-Match must hold a single ETag")
+
+// FromVersion derives a strong ETag from a row version.
+func FromVersion(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// ParseIfMatch returns the row version a request is conditioned on,
+// or nil when the request is unconditional. If-Match uses strong
+// comparison, so weak or foreign ETags can never match.
+func ParseIfMatch(header string) (*int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+	if strings.Contains(header, ",") {
+		return nil, ErrInvalidIfMatch
+	}
+	if strings.HasPrefix(header, "W/") {
+		return nil, repositories.ErrVersionMismatch
+	}
+
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return nil, ErrInvalidIfMatch
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil {
+		return nil, repositories.ErrVersionMismatch
+	}
+	return &version, nil
+}
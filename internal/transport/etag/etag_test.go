@@ -0,0 +1,58 @@
+package etag
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Oloruntobi1/grey/internal/repositories"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    int64
+		wantNil bool
+		wantErr error
+	}{
+		{header: "", wantNil: true},
+		{header: "  ", wantNil: true},
+		{header: "*", wantNil: true},
+		{header: `"3"`, want: 3},
+		{header: ` "42" `, want: 42},
+		{header: `W/"3"`, wantErr: repositories.ErrVersionMismatch},
+		{header: `"abc"`, wantErr: repositories.ErrVersionMismatch},
+		{header: `"3.5"`, wantErr: repositories.ErrVersionMismatch},
+		{header: `"99999999999999999999"`, wantErr: repositories.ErrVersionMismatch},
+		{header: `3`, wantErr: ErrInvalidIfMatch},
+		{header: `"3`, wantErr: ErrInvalidIfMatch},
+		{header: `"1", "2"`, wantErr: ErrInvalidIfMatch},
+	}
+	for _, tt := range tests {
+		got, err := ParseIfMatch(tt.header)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("ParseIfMatch(%q) error = %v, want %v", tt.header, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if tt.wantNil {
+			if got != nil {
+				t.Errorf("ParseIfMatch(%q) = %d, want nil", tt.header, *got)
+			}
+			continue
+		}
+		if got == nil || *got != tt.want {
+			t.Errorf("ParseIfMatch(%q) = %v, want %d", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestFromVersionRoundTrips(t *testing.T) {
+	for _, version := range []int64{0, 1, 1 << 40} {
+		got, err := ParseIfMatch(FromVersion(version))
+		if err != nil || got == nil || *got != version {
+			t.Errorf("version %d: got %v, %v", version, got, err)
+		}
+	}
+}
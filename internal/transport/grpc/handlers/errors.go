@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Oloruntobi1/grey/internal/transport/apierror"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain identifies this service in ErrorInfo details.
+const errorDomain = "grey"
+
+// toStatus converts a service error to a gRPC status. Unknown
+// errors are logged and hidden behind codes.Internal.
+func toStatus(ctx context.Context, logger *slog.Logger, err error) error {
+	if m, ok := apierror.Find(err); ok {
+		return statusWithReason(m.GRPCCode, m.Err.Error(), m.Reason)
+	}
+
+	logger.ErrorContext(
+		ctx,
+		"request_failed",
+		slog.Any("err", err),
+	)
+	return status.Error(codes.Internal, "internal server error")
+}
+
+func invalidID() error {
+	return statusWithReason(codes.InvalidArgument, "id must be a valid uuid", "invalid_id")
+}
+
+func statusWithReason(code codes.Code, message, reason string) error {
+	st, err := status.New(code, message).WithDetails(&errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: errorDomain,
+	})
+	if err != nil {
+		return status.Error(code, message)
+	}
+	return st.Err()
+}
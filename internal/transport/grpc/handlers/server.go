@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/Oloruntobi1/grey/internal/db/replica"
+	greyv1 "github.com/Oloruntobi1/grey/pkg/api/grey/v1"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// NewServer returns a gRPC server exposing the user, wallet and
+// transfer services. Every call is traced and measured through OTel using
+// the globally registered tracer and meter providers.
+func NewServer(userServer *UserServer, walletServer *WalletServer, transferServer *TransferServer, logger *slog.Logger) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(recoveryInterceptor(logger), sessionInterceptor),
+	)
+
+	greyv1.RegisterUserServiceServer(server, userServer)
+	greyv1.RegisterWalletServiceServer(server, walletServer)
+	greyv1.RegisterTransferServiceServer(server, transferServer)
+	reflection.Register(server)
+
+	return server
+}
+
+// recoveryInterceptor turns panics into codes.Internal
+// instead of crashing the process.
+func recoveryInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.ErrorContext(
+					ctx,
+					"grpc_handler_panicked",
+					slog.String("method", info.FullMethod),
+					slog.Any("panic", r),
+					slog.String("stack", string(debug.Stack())),
+				)
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// sessionInterceptor starts a replica session for every call, so reads
+// made after a write in the same call are served by the primary.
+func sessionInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(replica.WithSession(ctx), req)
+}
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	greyv1 "github.com/Oloruntobi1/grey/pkg/api/grey/v1"
+)
+
+// TransferServer publishes the transfer contract ahead of its
+// implementation, every call is answered with codes.Unimplemented.
+type TransferServer struct {
+	greyv1.UnimplementedTransferServiceServer
+}
+
+func NewTransferServer() *TransferServer {
+	return &TransferServer{}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/Oloruntobi1/grey/internal/models"
+	"github.com/Oloruntobi1/grey/internal/transport/etag"
+	"github.com/Oloruntobi1/grey/internal/transport/http/domains/users"
+	greyv1 "github.com/Oloruntobi1/grey/pkg/api/grey/v1"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// IfMatchMetadata carries the If-Match header of a gateway request,
+// UpdateUser checks it when the body sets no expected_version.
+const IfMatchMetadata = "if-match"
+
+type UserServer struct {
+	greyv1.UnimplementedUserServiceServer
+
+	svc    users.UserService
+	logger *slog.Logger
+}
+
+func NewUserServer(svc users.UserService, logger *slog.Logger) *UserServer {
+	return &UserServer{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+func (s *UserServer) CreateUser(ctx context.Context, req *greyv1.CreateUserRequest) (*greyv1.CreateUserResponse, error) {
+	id, err := s.svc.CreateUser(ctx, &models.User{
+		Name:  req.GetName(),
+		Email: req.GetEmail(),
+	})
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, err)
+	}
+	return &greyv1.CreateUserResponse{Id: id}, nil
+}
+
+func (s *UserServer) GetUser(ctx context.Context, req *greyv1.GetUserRequest) (*greyv1.GetUserResponse, error) {
+	if _, err := uuid.Parse(req.GetId()); err != nil {
+		return nil, invalidID()
+	}
+	user, err := s.svc.GetUser(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, err)
+	}
+	return &greyv1.GetUserResponse{User: userToProto(user)}, nil
+}
+
+func (s *UserServer) UpdateUser(ctx context.Context, req *greyv1.UpdateUserRequest) (*greyv1.UpdateUserResponse, error) {
+	if _, err := uuid.Parse(req.GetId()); err != nil {
+		return nil, invalidID()
+	}
+	if req.Name == nil && req.Email == nil {
+		return nil, statusWithReason(codes.InvalidArgument, "at least one of name or email is required", "empty_update")
+	}
+	if (req.Name != nil && req.GetName() == "") || (req.Email != nil && req.GetEmail() == "") {
+		return nil, statusWithReason(codes.InvalidArgument, "name and email cannot be empty", "invalid_body")
+	}
+	expectedVersion := req.ExpectedVersion
+	if expectedVersion == nil {
+		var err error
+		if expectedVersion, err = ifMatchVersion(ctx); err != nil {
+			if errors.Is(err, etag.ErrInvalidIfMatch) {
+				return nil, statusWithReason(codes.InvalidArgument, err.Error(), "invalid_if_match")
+			}
+			return nil, toStatus(ctx, s.logger, err)
+		}
+	}
+	user, err := s.svc.UpdateUser(ctx, req.GetId(), &models.UserUpdate{
+		Name:  req.Name,
+		Email: req.Email,
+	}, expectedVersion)
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, err)
+	}
+	return &greyv1.UpdateUserResponse{User: userToProto(user)}, nil
+}
+
+func (s *UserServer) DeleteUser(ctx context.Context, req *greyv1.DeleteUserRequest) (*greyv1.DeleteUserResponse, error) {
+	if _, err := uuid.Parse(req.GetId()); err != nil {
+		return nil, invalidID()
+	}
+	if err := s.svc.DeleteUser(ctx, req.GetId()); err != nil {
+		return nil, toStatus(ctx, s.logger, err)
+	}
+	return &greyv1.DeleteUserResponse{}, nil
+}
+
+func (s *UserServer) RestoreUser(ctx context.Context, req *greyv1.RestoreUserRequest) (*greyv1.RestoreUserResponse, error) {
+	if _, err := uuid.Parse(req.GetId()); err != nil {
+		return nil, invalidID()
+	}
+	if err := s.svc.RestoreUser(ctx, req.GetId()); err != nil {
+		return nil, toStatus(ctx, s.logger, err)
+	}
+	return &greyv1.RestoreUserResponse{}, nil
+}
+
+// ifMatchVersion returns the version of the If-Match metadata,
+// nil when the request carries none.
+func ifMatchVersion(ctx context.Context) (*int64, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(IfMatchMetadata)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	if len(values) > 1 {
+		return nil, etag.ErrInvalidIfMatch
+	}
+	return etag.ParseIfMatch(values[0])
+}
+
+func userToProto(user *models.User) *greyv1.User {
+	u := &greyv1.User{
+		Id:        user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Version:   user.Version,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+	}
+	if !user.UpdatedAt.IsZero() {
+		u.UpdatedAt = timestamppb.New(user.UpdatedAt)
+	}
+	return u
+}
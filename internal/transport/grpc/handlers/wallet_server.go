@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Oloruntobi1/grey/internal/models"
+	"github.com/Oloruntobi1/grey/internal/transport/http/domains/wallets"
+	greyv1 "github.com/Oloruntobi1/grey/pkg/api/grey/v1"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var walletStatuses = map[models.WalletStatus]greyv1.WalletStatus{
+	models.WalletActive:        greyv1.WalletStatus_WALLET_STATUS_ACTIVE,
+	models.WalletFrozen:        greyv1.WalletStatus_WALLET_STATUS_FROZEN,
+	models.WalletDebitBlocked:  greyv1.WalletStatus_WALLET_STATUS_DEBIT_BLOCKED,
+	models.WalletCreditBlocked: greyv1.WalletStatus_WALLET_STATUS_CREDIT_BLOCKED,
+	models.WalletClosed:        greyv1.WalletStatus_WALLET_STATUS_CLOSED,
+}
+
+type WalletServer struct {
+	greyv1.UnimplementedWalletServiceServer
+
+	svc    wallets.WalletService
+	logger *slog.Logger
+}
+
+func NewWalletServer(svc wallets.WalletService, logger *slog.Logger) *WalletServer {
+	return &WalletServer{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+func (s *WalletServer) CreateWallet(ctx context.Context, req *greyv1.CreateWalletRequest) (*greyv1.CreateWalletResponse, error) {
+	if _, err := uuid.Parse(req.GetUserId()); err != nil {
+		return nil, statusWithReason(codes.InvalidArgument, "user_id must be a valid uuid", "invalid_body")
+	}
+	balance := decimal.Zero
+	if req.GetInitialBalance() != "" {
+		var err error
+		balance, err = decimal.NewFromString(req.GetInitialBalance())
+		if err != nil {
+			return nil, statusWithReason(codes.InvalidArgument, "initial_balance must be a decimal number", "invalid_body")
+		}
+	}
+	id, err := s.svc.CreateWallet(ctx, &models.Wallet{
+		UserID:  req.GetUserId(),
+		Balance: balance,
+	})
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, err)
+	}
+	return &greyv1.CreateWalletResponse{Id: id}, nil
+}
+
+func (s *WalletServer) GetWallet(ctx context.Context, req *greyv1.GetWalletRequest) (*greyv1.GetWalletResponse, error) {
+	if _, err := uuid.Parse(req.GetId()); err != nil {
+		return nil, invalidID()
+	}
+	wallet, err := s.svc.GetWallet(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, err)
+	}
+	return &greyv1.GetWalletResponse{Wallet: walletToProto(wallet)}, nil
+}
+
+func (s *WalletServer) DeleteWallet(ctx context.Context, req *greyv1.DeleteWalletRequest) (*greyv1.DeleteWalletResponse, error) {
+	if _, err := uuid.Parse(req.GetId()); err != nil {
+		return nil, invalidID()
+	}
+	if err := s.svc.DeleteWallet(ctx, req.GetId()); err != nil {
+		return nil, toStatus(ctx, s.logger, err)
+	}
+	return &greyv1.DeleteWalletResponse{}, nil
+}
+
+func (s *WalletServer) RestoreWallet(ctx context.Context, req *greyv1.RestoreWalletRequest) (*greyv1.RestoreWalletResponse, error) {
+	if _, err := uuid.Parse(req.GetId()); err != nil {
+		return nil, invalidID()
+	}
+	if err := s.svc.RestoreWallet(ctx, req.GetId()); err != nil {
+		return nil, toStatus(ctx, s.logger, err)
+	}
+	return &greyv1.RestoreWalletResponse{}, nil
+}
+
+func (s *WalletServer) ChangeWalletStatus(ctx context.Context, req *greyv1.ChangeWalletStatusRequest) (*greyv1.ChangeWalletStatusResponse, error) {
+	if _, err := uuid.Parse(req.GetId()); err != nil {
+		return nil, invalidID()
+	}
+	// an unknown enum value maps to the empty status which
+	// the service rejects as invalid
+	var next models.WalletStatus
+	for modelStatus, protoStatus := range walletStatuses {
+		if protoStatus == req.GetStatus() {
+			next = modelStatus
+		}
+	}
+	wallet, err := s.svc.ChangeWalletStatus(ctx, req.GetId(), next, req.GetReason())
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, err)
+	}
+	return &greyv1.ChangeWalletStatusResponse{Wallet: walletToProto(wallet)}, nil
+}
+
+func walletToProto(wallet *models.Wallet) *greyv1.Wallet {
+	return &greyv1.Wallet{
+		Id:           wallet.ID,
+		UserId:       wallet.UserID,
+		Balance:      wallet.Balance.String(),
+		Status:       walletStatuses[wallet.Status],
+		StatusReason: wallet.StatusReason,
+		CreatedAt:    timestamppb.New(wallet.CreatedAt),
+	}
+}
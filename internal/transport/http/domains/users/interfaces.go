@@ -8,4 +8,8 @@ import (
 
 type UserAdapter interface {
 	CreateUser(ctx context.Context, userModel *models.User) (string, error)
+	DeleteUser(ctx context.Context, id string) error
+	RestoreUser(ctx context.Context, id string) error
+	GetUser(ctx context.Context, id string) (*models.User, error)
+	UpdateUser(ctx context.Context, id string, update *models.UserUpdate, expectedVersion *int64) (*models.User, error)
 }
@@ -0,0 +1,62 @@
+package users
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Oloruntobi1/grey/internal/repositories"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type userMetrics struct {
+	created      metric.Int64Counter
+	createFailed metric.Int64Counter
+}
+
+func newUserMetrics() (*userMetrics, error) {
+	meter := otel.Meter("userService")
+
+	created, err := meter.Int64Counter(
+		"grey.users.created",
+		metric.WithDescription("Number of users created."),
+		metric.WithUnit("{user}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	createFailed, err := meter.Int64Counter(
+		"grey.users.create.failed",
+		metric.WithDescription("Number of users that could not be created, by reason."),
+		metric.WithUnit("{user}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userMetrics{
+		created:      created,
+		createFailed: createFailed,
+	}, nil
+}
+
+func (m *userMetrics) recordCreate(ctx context.Context, err error) {
+	if err == nil {
+		m.created.Add(ctx, 1)
+		return
+	}
+	m.createFailed.Add(ctx, 1, metric.WithAttributes(attribute.String("reason", createFailureReason(err))))
+}
+
+// createFailureReason keeps the reason attribute to a fixed set of values.
+func createFailureReason(err error) string {
+	switch {
+	case errors.Is(err, repositories.ErrUserAlreadyExists):
+		return "already_exists"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	default:
+		return "internal"
+	}
+}
@@ -3,17 +3,52 @@ package users
 import (
 	"context"
 
+	"github.com/Oloruntobi1/grey/internal/auth"
+	"github.com/Oloruntobi1/grey/internal/db/replica"
 	"github.com/Oloruntobi1/grey/internal/models"
 )
 
 type UserService struct {
 	userRepo UserAdapter
+	metrics  *userMetrics
+
+	// admins are the services allowed to restore users.
+	admins []string
 }
 
-func NewUserService(userRepo UserAdapter) *UserService {
-	return &UserService{userRepo: userRepo}
+func NewUserService(userRepo UserAdapter, admins []string) (*UserService, error) {
+	metrics, err := newUserMetrics()
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserService{userRepo: userRepo, metrics: metrics, admins: admins}, nil
 }
 
 func (s *UserService) CreateUser(ctx context.Context, user *models.User) (string, error) {
-	return s.userRepo.CreateUser(ctx, user)
+	id, err := s.userRepo.CreateUser(ctx, user)
+	s.metrics.recordCreate(ctx, err)
+	return id, err
+}
+
+// GetUser reads from the primary. The version is handed out as the ETag
+// conditional updates are checked against, one read from a lagging
+// replica would make them fail with a version mismatch.
+func (s *UserService) GetUser(ctx context.Context, id string) (*models.User, error) {
+	return s.userRepo.GetUser(replica.UsePrimary(ctx), id)
+}
+
+func (s *UserService) UpdateUser(ctx context.Context, id string, update *models.UserUpdate, expectedVersion *int64) (*models.User, error) {
+	return s.userRepo.UpdateUser(ctx, id, update, expectedVersion)
+}
+
+func (s *UserService) DeleteUser(ctx context.Context, id string) error {
+	return s.userRepo.DeleteUser(ctx, id)
+}
+
+func (s *UserService) RestoreUser(ctx context.Context, id string) error {
+	if err := auth.RequireService(ctx, s.admins...); err != nil {
+		return err
+	}
+	return s.userRepo.RestoreUser(ctx, id)
 }
@@ -8,4 +8,9 @@ import (
 
 type WalletAdapter interface {
 	CreateWallet(ctx context.Context, walletModel *models.Wallet) (string, error)
+	DeleteWallet(ctx context.Context, id string) error
+	RestoreWallet(ctx context.Context, id string) error
+	GetWallet(ctx context.Context, id string) (*models.Wallet, error)
+	UpdateWalletStatus(ctx context.Context, id string, from, to models.WalletStatus, reason string) (*models.Wallet, error)
+	WalletBalances(ctx context.Context) ([]models.WalletBalance, error)
 }
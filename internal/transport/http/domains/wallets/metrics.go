@@ -0,0 +1,99 @@
+package wallets
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Oloruntobi1/grey/internal/repositories"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type walletMetrics struct {
+	meter        metric.Meter
+	created      metric.Int64Counter
+	createFailed metric.Int64Counter
+}
+
+func newWalletMetrics() (*walletMetrics, error) {
+	meter := otel.Meter("walletService")
+
+	created, err := meter.Int64Counter(
+		"grey.wallets.created",
+		metric.WithDescription("Number of wallets created."),
+		metric.WithUnit("{wallet}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	createFailed, err := meter.Int64Counter(
+		"grey.wallets.create.failed",
+		metric.WithDescription("Number of wallets that could not be created, by reason."),
+		metric.WithUnit("{wallet}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &walletMetrics{
+		meter:        meter,
+		created:      created,
+		createFailed: createFailed,
+	}, nil
+}
+
+func (m *walletMetrics) recordCreate(ctx context.Context, err error) {
+	if err == nil {
+		m.created.Add(ctx, 1)
+		return
+	}
+	m.createFailed.Add(ctx, 1, metric.WithAttributes(attribute.String("reason", createFailureReason(err))))
+}
+
+// createFailureReason keeps the reason attribute to a fixed set of values.
+func createFailureReason(err error) string {
+	switch {
+	case errors.Is(err, repositories.ErrUserNotFound):
+		return "user_not_found"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	default:
+		return "internal"
+	}
+}
+
+// RegisterBalanceMetrics reports the number of wallets and their total
+// balance by status every time metrics are collected. Unregister the
+// returned registration before closing the database pool.
+func (s *WalletService) RegisterBalanceMetrics() (metric.Registration, error) {
+	count, err := s.metrics.meter.Int64ObservableGauge(
+		"grey.wallets",
+		metric.WithDescription("Number of wallets that are not deleted, by status."),
+		metric.WithUnit("{wallet}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := s.metrics.meter.Float64ObservableGauge(
+		"grey.wallets.balance",
+		metric.WithDescription("Total balance of the wallets that are not deleted, by status."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.metrics.meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		balances, err := s.userRepo.WalletBalances(ctx)
+		if err != nil {
+			return err
+		}
+		for _, b := range balances {
+			status := metric.WithAttributes(attribute.String("status", string(b.Status)))
+			o.ObserveInt64(count, b.Wallets, status)
+			value, _ := b.Balance.Float64()
+			o.ObserveFloat64(balance, value, status)
+		}
+		return nil
+	}, count, balance)
+}
@@ -2,18 +2,86 @@ package wallets
 
 import (
 	"context"
+	"errors"
+	"strings"
 
+	"github.com/Oloruntobi1/grey/internal/auth"
+	"github.com/Oloruntobi1/grey/internal/db/replica"
 	"github.com/Oloruntobi1/grey/internal/models"
+	"github.com/Oloruntobi1/grey/internal/repositories"
+)
+
+var (
+	ErrInvalidWalletStatus     = errors.New("invalid wallet status")
+	ErrInvalidStatusTransition = errors.New("wallet status transition not allowed")
+	ErrStatusReasonRequired    = errors.New("a reason is required to change wallet status")
 )
 
 type WalletService struct {
 	userRepo WalletAdapter
+	metrics  *walletMetrics
+
+	// admins are the services allowed to restore
+	// wallets and change their status.
+	admins []string
 }
 
-func NewWalletService(userRepo WalletAdapter) *WalletService {
-	return &WalletService{userRepo: userRepo}
+func NewWalletService(userRepo WalletAdapter, admins []string) (*WalletService, error) {
+	metrics, err := newWalletMetrics()
+	if err != nil {
+		return nil, err
+	}
+
+	return &WalletService{userRepo: userRepo, metrics: metrics, admins: admins}, nil
 }
 
 func (s *WalletService) CreateWallet(ctx context.Context, user *models.Wallet) (string, error) {
-	return s.userRepo.CreateWallet(ctx, user)
+	id, err := s.userRepo.CreateWallet(ctx, user)
+	s.metrics.recordCreate(ctx, err)
+	return id, err
+}
+
+func (s *WalletService) GetWallet(ctx context.Context, id string) (*models.Wallet, error) {
+	return s.userRepo.GetWallet(ctx, id)
+}
+
+// ChangeWalletStatus moves a wallet through its status state machine.
+// A wallet can only be closed once its balance is zero.
+func (s *WalletService) ChangeWalletStatus(ctx context.Context, id string, status models.WalletStatus, reason string) (*models.Wallet, error) {
+	if err := auth.RequireService(ctx, s.admins...); err != nil {
+		return nil, err
+	}
+	if !status.Valid() {
+		return nil, ErrInvalidWalletStatus
+	}
+	if strings.TrimSpace(reason) == "" {
+		return nil, ErrStatusReasonRequired
+	}
+
+	// The transition is checked against what the primary holds,
+	// a lagging replica would only make the update fail as stale.
+	ctx = replica.UsePrimary(ctx)
+	wallet, err := s.userRepo.GetWallet(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !wallet.Status.CanTransitionTo(status) {
+		return nil, ErrInvalidStatusTransition
+	}
+	if status == models.WalletClosed && !wallet.Balance.IsZero() {
+		return nil, repositories.ErrWalletHasBalance
+	}
+
+	return s.userRepo.UpdateWalletStatus(ctx, id, wallet.Status, status, reason)
+}
+
+func (s *WalletService) DeleteWallet(ctx context.Context, id string) error {
+	return s.userRepo.DeleteWallet(ctx, id)
+}
+
+func (s *WalletService) RestoreWallet(ctx context.Context, id string) error {
+	if err := auth.RequireService(ctx, s.admins...); err != nil {
+		return err
+	}
+	return s.userRepo.RestoreWallet(ctx, id)
 }
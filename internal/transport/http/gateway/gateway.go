@@ -0,0 +1,146 @@
+// Package gateway serves the REST routes generated from the protobuf
+// contract in proto/. The routes, request and response types and the
+// OpenAPI document all come from that one contract, so the REST and
+// gRPC surfaces cannot drift apart.
+package gateway
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/Oloruntobi1/grey/internal/transport/apierror"
+	"github.com/Oloruntobi1/grey/internal/transport/etag"
+	grpchandlers "github.com/Oloruntobi1/grey/internal/transport/grpc/handlers"
+	greyv1 "github.com/Oloruntobi1/grey/pkg/api/grey/v1"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// NewHandler returns the generated REST routes. Requests are served in
+// process by the given gRPC servers without going over the network.
+func NewHandler(
+	ctx context.Context,
+	userServer greyv1.UserServiceServer,
+	walletServer greyv1.WalletServiceServer,
+	transferServer greyv1.TransferServiceServer,
+	logger *slog.Logger,
+) (http.Handler, error) {
+	routes, err := documentedRoutes()
+	if err != nil {
+		return nil, err
+	}
+
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames: true,
+			},
+		}),
+		runtime.WithIncomingHeaderMatcher(incomingHeader),
+		runtime.WithForwardResponseOption(setETag),
+		runtime.WithErrorHandler(errorHandler(logger)),
+		runtime.WithRoutingErrorHandler(routingErrorHandler(logger, routes)),
+	)
+
+	if err := greyv1.RegisterUserServiceHandlerServer(ctx, mux, userServer); err != nil {
+		return nil, err
+	}
+	if err := greyv1.RegisterWalletServiceHandlerServer(ctx, mux, walletServer); err != nil {
+		return nil, err
+	}
+	if err := greyv1.RegisterTransferServiceHandlerServer(ctx, mux, transferServer); err != nil {
+		return nil, err
+	}
+
+	// the server span comes from the otelhttp handler wrapping the router
+	return mux, nil
+}
+
+// incomingHeader forwards If-Match to the gRPC servers as metadata, so
+// PATCH /v1/users/{id} can be made conditional the same way as the
+// hand written route. Other headers are forwarded as by default.
+func incomingHeader(key string) (string, bool) {
+	if strings.EqualFold(key, "If-Match") {
+		return grpchandlers.IfMatchMetadata, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// setETag sends the version of a returned user as its ETag,
+// which is what If-Match is checked against.
+func setETag(_ context.Context, w http.ResponseWriter, msg proto.Message) error {
+	var user *greyv1.User
+	switch resp := msg.(type) {
+	case *greyv1.GetUserResponse:
+		user = resp.GetUser()
+	case *greyv1.UpdateUserResponse:
+		user = resp.GetUser()
+	}
+	if user != nil {
+		w.Header().Set("ETag", etag.FromVersion(user.GetVersion()))
+	}
+	return nil
+}
+
+// errorHandler writes gRPC errors in the same Error envelope and with
+// the same status as the hand written handlers, using the ErrorInfo
+// reason as the code.
+func errorHandler(logger *slog.Logger) runtime.ErrorHandlerFunc {
+	return func(ctx context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+		st := status.Convert(err)
+
+		code := snakeCase(st.Code().String())
+		for _, detail := range st.Details() {
+			if info, ok := detail.(*errdetails.ErrorInfo); ok {
+				code = info.Reason
+			}
+		}
+
+		httpStatus := runtime.HTTPStatusFromCode(st.Code())
+		if m, ok := apierror.FindReason(code); ok {
+			httpStatus = m.HTTPStatus
+		}
+		apierror.WriteError(ctx, w, logger, httpStatus, code, st.Message())
+	}
+}
+
+// routingErrorHandler answers unknown routes and methods the same way
+// as the hand written router, including the Allow header on a 405.
+func routingErrorHandler(logger *slog.Logger, routes []route) runtime.RoutingErrorHandlerFunc {
+	return func(ctx context.Context, mux *runtime.ServeMux, m runtime.Marshaler, w http.ResponseWriter, r *http.Request, httpStatus int) {
+		switch httpStatus {
+		case http.StatusNotFound:
+			apierror.WriteError(ctx, w, logger, httpStatus, "route_not_found", "no route matches "+r.URL.Path)
+		case http.StatusMethodNotAllowed:
+			if allowed := allowedMethods(routes, r.URL.Path); len(allowed) > 0 {
+				w.Header().Set("Allow", strings.Join(allowed, ", "))
+			}
+			apierror.WriteError(ctx, w, logger, httpStatus, "method_not_allowed",
+				r.Method+" is not allowed on "+r.URL.Path)
+		default:
+			runtime.DefaultRoutingErrorHandler(ctx, mux, m, w, r, httpStatus)
+		}
+	}
+}
+
+// snakeCase turns a gRPC code name such as FailedPrecondition
+// into failed_precondition.
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
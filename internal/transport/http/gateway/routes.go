@@ -0,0 +1,74 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Oloruntobi1/grey/pkg/api"
+)
+
+// route is a path template of the generated OpenAPI
+// document and the methods it is served for.
+type route struct {
+	segments []string
+	methods  []string
+}
+
+// documentedRoutes reads the generated routes from the embedded
+// swagger document, the runtime mux does not expose them.
+func documentedRoutes() ([]route, error) {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(api.SwaggerJSON, &spec); err != nil {
+		return nil, fmt.Errorf("invalid swagger document: %w", err)
+	}
+
+	routes := make([]route, 0, len(spec.Paths))
+	for path, operations := range spec.Paths {
+		r := route{segments: strings.Split(strings.Trim(path, "/"), "/")}
+		for method := range operations {
+			r.methods = append(r.methods, strings.ToUpper(method))
+		}
+		routes = append(routes, r)
+	}
+	return routes, nil
+}
+
+// allowedMethods returns the methods of every route matching path,
+// a {param} segment of a template matches any single segment.
+func allowedMethods(routes []route, path string) []string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	seen := map[string]bool{}
+	for _, r := range routes {
+		if !r.matches(segments) {
+			continue
+		}
+		for _, method := range r.methods {
+			seen[method] = true
+		}
+	}
+
+	allowed := make([]string, 0, len(seen))
+	for method := range seen {
+		allowed = append(allowed, method)
+	}
+	sort.Strings(allowed)
+	return allowed
+}
+
+func (r route) matches(segments []string) bool {
+	if len(r.segments) != len(segments) {
+		return false
+	}
+	for i, s := range r.segments {
+		if strings.HasPrefix(s, "{") || s == segments[i] {
+			continue
+		}
+		return false
+	}
+	return true
+}
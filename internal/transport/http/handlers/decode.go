@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/Oloruntobi1/grey/internal/transport/apierror"
+)
+
+// decodeError describes why a request body was rejected,
+// it is written to the client as an Error envelope.
+type decodeError struct {
+	status  int
+	code    string
+	message string
+	fields  []apierror.ErrorField
+}
+
+// decodeJSON decodes a single JSON object from the request body into dst.
+// The body must be application/json, no larger than maxBytes and
+// may only contain fields known to dst.
+func decodeJSON(w http.ResponseWriter, r *http.Request, maxBytes int64, dst interface{}) *decodeError {
+	if err := checkContentType(r); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		return newDecodeError(err)
+	}
+	// anything but EOF after the first value is trailing garbage
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return newDecodeError(err)
+		}
+		return &decodeError{
+			status:  http.StatusBadRequest,
+			code:    "invalid_body",
+			message: "request body must contain a single JSON value",
+		}
+	}
+
+	return nil
+}
+
+// limitJSONBody applies the rules of decodeJSON to handlers decoding
+// bodies themselves, such as the gateway. The body is read up front so
+// one that is too large is answered with a 413 instead of a decoding
+// error, an empty body is left for the handler to judge.
+func limitJSONBody(maxBytes int64, logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+		if err != nil {
+			writeDecodeError(r.Context(), w, logger, newDecodeError(err))
+			return
+		}
+		if len(body) > 0 {
+			if err := checkContentType(r); err != nil {
+				writeDecodeError(r.Context(), w, logger, err)
+				return
+			}
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+func checkContentType(r *http.Request) *decodeError {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return &decodeError{
+			status:  http.StatusUnsupportedMediaType,
+			code:    "unsupported_media_type",
+			message: "Content-Type must be application/json",
+		}
+	}
+	return nil
+}
+
+// newDecodeError maps decoder errors to messages that do not leak Go
+// types and, where the decoder names one, to the offending field.
+func newDecodeError(err error) *decodeError {
+	var (
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+		maxBytesErr *http.MaxBytesError
+	)
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return &decodeError{
+			status:  http.StatusRequestEntityTooLarge,
+			code:    "body_too_large",
+			message: fmt.Sprintf("request body must not be larger than %d bytes", maxBytesErr.Limit),
+		}
+	case errors.Is(err, io.EOF):
+		return &decodeError{
+			status:  http.StatusBadRequest,
+			code:    "invalid_body",
+			message: "request body must not be empty",
+		}
+	case errors.As(err, &syntaxErr):
+		return &decodeError{
+			status:  http.StatusBadRequest,
+			code:    "invalid_body",
+			message: fmt.Sprintf("request body contains malformed JSON at offset %d", syntaxErr.Offset),
+		}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &decodeError{
+			status:  http.StatusBadRequest,
+			code:    "invalid_body",
+			message: "request body contains malformed JSON",
+		}
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return &decodeError{
+				status:  http.StatusBadRequest,
+				code:    "invalid_body",
+				message: "request body must be a JSON object",
+			}
+		}
+		return invalidFields(apierror.ErrorField{
+			Name:    apierror.String(typeErr.Field),
+			Message: apierror.String("must be " + jsonTypeName(typeErr.Type.Kind().String())),
+		})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// the decoder has no typed error for unknown fields
+		name := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return invalidFields(apierror.ErrorField{
+			Name:    apierror.String(name),
+			Message: apierror.String("unknown field"),
+		})
+	default:
+		// errors returned by UnmarshalJSON of field types such as decimal
+		return &decodeError{
+			status:  http.StatusBadRequest,
+			code:    "invalid_body",
+			message: err.Error(),
+		}
+	}
+}
+
+func invalidFields(fields ...apierror.ErrorField) *decodeError {
+	return &decodeError{
+		status:  http.StatusBadRequest,
+		code:    "invalid_fields",
+		message: "request body has invalid fields",
+		fields:  fields,
+	}
+}
+
+// jsonTypeName names a Go kind the way a JSON client would know it,
+// with its article.
+func jsonTypeName(kind string) string {
+	switch {
+	case strings.HasPrefix(kind, "int"), strings.HasPrefix(kind, "uint"), strings.HasPrefix(kind, "float"):
+		return "a number"
+	case kind == "bool":
+		return "a boolean"
+	case kind == "slice", kind == "array":
+		return "an array"
+	case kind == "struct", kind == "map":
+		return "an object"
+	default:
+		return "a " + kind
+	}
+}
+
+func writeDecodeError(ctx context.Context, w http.ResponseWriter, logger *slog.Logger, err *decodeError) {
+	apierror.WriteError(ctx, w, logger, err.status, err.code, err.message, err.fields...)
+}
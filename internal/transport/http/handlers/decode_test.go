@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+type decodeTarget struct {
+	Name    string          `json:"name"`
+	Age     int             `json:"age"`
+	Tags    []string        `json:"tags"`
+	Active  bool            `json:"active"`
+	Balance decimal.Decimal `json:"balance"`
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		maxBytes    int64
+		wantStatus  int
+		wantCode    string
+		wantField   string
+		wantMessage string
+	}{
+		{name: "valid", body: `{"name": "a", "age": 3}`},
+		{name: "charset parameter", contentType: "application/json; charset=utf-8", body: `{"name": "a"}`},
+		{name: "wrong content type", contentType: "text/plain", body: `{"name": "a"}`, wantStatus: 415, wantCode: "unsupported_media_type"},
+		{name: "missing content type", contentType: "-", body: `{"name": "a"}`, wantStatus: 415, wantCode: "unsupported_media_type"},
+		{name: "empty", body: ``, wantStatus: 400, wantCode: "invalid_body", wantMessage: "request body must not be empty"},
+		{name: "malformed", body: `{"name": }`, wantStatus: 400, wantCode: "invalid_body", wantMessage: "request body contains malformed JSON at offset 10"},
+		{name: "truncated", body: `{"name": "a"`, wantStatus: 400, wantCode: "invalid_body", wantMessage: "request body contains malformed JSON"},
+		{name: "unknown field", body: `{"name": "a", "admin": true}`, wantStatus: 400, wantCode: "invalid_fields", wantField: "admin", wantMessage: "unknown field"},
+		{name: "trailing value", body: `{"name": "a"} {"name": "b"}`, wantStatus: 400, wantCode: "invalid_body", wantMessage: "request body must contain a single JSON value"},
+		{name: "trailing garbage", body: `{"name": "a"} x`, wantStatus: 400, wantCode: "invalid_body"},
+		{name: "trailing whitespace", body: "{\"name\": \"a\"}\n"},
+		{name: "not an object", body: `["a"]`, wantStatus: 400, wantCode: "invalid_body", wantMessage: "request body must be a JSON object"},
+		{name: "string for number", body: `{"age": "3"}`, wantStatus: 400, wantCode: "invalid_fields", wantField: "age", wantMessage: "must be a number"},
+		{name: "number for string", body: `{"name": 3}`, wantStatus: 400, wantCode: "invalid_fields", wantField: "name", wantMessage: "must be a string"},
+		{name: "object for array", body: `{"tags": {}}`, wantStatus: 400, wantCode: "invalid_fields", wantField: "tags", wantMessage: "must be an array"},
+		{name: "string for boolean", body: `{"active": "yes"}`, wantStatus: 400, wantCode: "invalid_fields", wantField: "active", wantMessage: "must be a boolean"},
+		{name: "invalid decimal", body: `{"balance": "lots"}`, wantStatus: 400, wantCode: "invalid_body"},
+		{name: "too large", body: `{"name": "` + strings.Repeat("a", 64) + `"}`, maxBytes: 32, wantStatus: 413, wantCode: "body_too_large", wantMessage: "request body must not be larger than 32 bytes"},
+		{name: "too large after the value", body: `{"name": "a"}` + strings.Repeat(" ", 64), maxBytes: 32, wantStatus: 413, wantCode: "body_too_large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			switch tt.contentType {
+			case "":
+				r.Header.Set("Content-Type", "application/json")
+			case "-":
+			default:
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			maxBytes := tt.maxBytes
+			if maxBytes == 0 {
+				maxBytes = 1 << 20
+			}
+
+			var dst decodeTarget
+			err := decodeJSON(httptest.NewRecorder(), r, maxBytes, &dst)
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("got %+v, want no error", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			if err.status != tt.wantStatus || err.code != tt.wantCode {
+				t.Errorf("got %d %s, want %d %s", err.status, err.code, tt.wantStatus, tt.wantCode)
+			}
+
+			message := err.message
+			if tt.wantField != "" {
+				if len(err.fields) != 1 || *err.fields[0].Name != tt.wantField {
+					t.Fatalf("got fields %+v, want %s", err.fields, tt.wantField)
+				}
+				message = *err.fields[0].Message
+			}
+			if tt.wantMessage != "" && message != tt.wantMessage {
+				t.Errorf("got message %q, want %q", message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestLimitJSONBody(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantBody    string
+	}{
+		{name: "valid", contentType: "application/json", body: `{"name": "a"}`, wantStatus: 200, wantBody: `{"name": "a"}`},
+		{name: "empty without content type", body: ``, wantStatus: 200},
+		{name: "wrong content type", contentType: "text/plain", body: `{"name": "a"}`, wantStatus: 415},
+		{name: "too large", contentType: "application/json", body: strings.Repeat("a", 64), wantStatus: 413},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := limitJSONBody(32, logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				got = string(body)
+			}))
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got != tt.wantBody {
+				t.Errorf("handler read %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Oloruntobi1/grey/pkg/api"
+)
+
+// openAPISpec documents the hand written routes registered in
+// SetupRouter, the generated ones are merged in by openAPIDocument.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+//go:embed docs.html
+var docsPage []byte
+
+// redocScript is the Redoc standalone bundle, served by this server so
+// the docs page does not depend on a CDN. It is the unmodified npm build
+// of Redoc 2.0.0-rc.59 (commit 9f564d3), licensed under the MIT license
+// at https://github.com/Redocly/redoc/blob/v2.0.0-rc.59/LICENSE. The
+// notices of the packages bundled into it, which its header refers to,
+// are published alongside it at
+// https://cdn.jsdelivr.net/npm/redoc@2.0.0-rc.59/bundles/redoc.standalone.js.LICENSE.txt
+//
+//go:embed redoc.standalone.js
+var redocScript []byte
+
+// generatedSchemas maps definitions of the generated swagger document
+// to the schemas replacing them, the gateway writes its errors in the
+// Error envelope rather than as a google.rpc.Status.
+var generatedSchemas = map[string]string{
+	"rpcStatus":   "Error",
+	"protobufAny": "",
+}
+
+func OpenAPIHandler(spec []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(spec)
+	}
+}
+
+func DocsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(docsPage)
+	}
+}
+
+func RedocHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		_, _ = w.Write(redocScript)
+	}
+}
+
+// openAPIDocument returns openapi.json with the paths and definitions
+// of the swagger document generated from the protobuf contract, turned
+// into OpenAPI 3.1, so one document describes every route.
+func openAPIDocument() ([]byte, error) {
+	var spec map[string]any
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		return nil, fmt.Errorf("invalid openapi.json: %w", err)
+	}
+	var swagger struct {
+		Tags        []any                     `json:"tags"`
+		Paths       map[string]map[string]any `json:"paths"`
+		Definitions map[string]any            `json:"definitions"`
+	}
+	if err := json.Unmarshal(api.SwaggerJSON, &swagger); err != nil {
+		return nil, fmt.Errorf("invalid swagger document: %w", err)
+	}
+
+	paths, _ := spec["paths"].(map[string]any)
+	components, _ := spec["components"].(map[string]any)
+	schemas, _ := components["schemas"].(map[string]any)
+	if paths == nil || schemas == nil {
+		return nil, fmt.Errorf("invalid openapi.json: paths and components.schemas are required")
+	}
+
+	for path, operations := range swagger.Paths {
+		if _, ok := paths[path]; ok {
+			return nil, fmt.Errorf("%s is both hand written and generated", path)
+		}
+		item := map[string]any{}
+		for method, operation := range operations {
+			op, ok := operation.(map[string]any)
+			if !ok {
+				continue
+			}
+			item[method] = openAPIOperation(op)
+		}
+		paths[path] = item
+	}
+
+	for name, schema := range swagger.Definitions {
+		if _, ok := generatedSchemas[name]; ok {
+			continue
+		}
+		if _, ok := schemas[name]; ok {
+			return nil, fmt.Errorf("schema %s is both hand written and generated", name)
+		}
+		schemas[name] = openAPIRefs(schema)
+	}
+
+	if tags, ok := spec["tags"].([]any); ok {
+		spec["tags"] = append(tags, swagger.Tags...)
+	}
+
+	return json.MarshalIndent(spec, "", "  ")
+}
+
+// openAPIOperation moves the body parameter of a swagger operation to
+// its requestBody and the schemas of parameters, responses and response
+// headers under schema and content, the other fields are the same in
+// both versions.
+func openAPIOperation(op map[string]any) map[string]any {
+	out := map[string]any{}
+	for key, value := range op {
+		out[key] = openAPIRefs(value)
+	}
+
+	if params, ok := op["parameters"].([]any); ok {
+		var converted []any
+		for _, p := range params {
+			param, ok := openAPIRefs(p).(map[string]any)
+			if !ok {
+				continue
+			}
+			if param["in"] == "body" {
+				body := map[string]any{
+					"content": map[string]any{"application/json": map[string]any{"schema": param["schema"]}},
+				}
+				if required, ok := param["required"]; ok {
+					body["required"] = required
+				}
+				out["requestBody"] = body
+				continue
+			}
+			schema := map[string]any{}
+			for _, key := range []string{"type", "format", "items", "enum", "default"} {
+				if v, ok := param[key]; ok {
+					schema[key] = v
+					delete(param, key)
+				}
+			}
+			delete(param, "collectionFormat")
+			param["schema"] = schema
+			converted = append(converted, param)
+		}
+		if len(converted) > 0 {
+			out["parameters"] = converted
+		} else {
+			delete(out, "parameters")
+		}
+	}
+
+	if responses, ok := out["responses"].(map[string]any); ok {
+		for code, r := range responses {
+			response, ok := r.(map[string]any)
+			if !ok {
+				continue
+			}
+			if schema, ok := response["schema"]; ok {
+				delete(response, "schema")
+				response["content"] = map[string]any{"application/json": map[string]any{"schema": schema}}
+			}
+			if headers, ok := response["headers"].(map[string]any); ok {
+				for name, h := range headers {
+					header, ok := h.(map[string]any)
+					if !ok {
+						continue
+					}
+					converted := map[string]any{"schema": map[string]any{"type": header["type"]}}
+					if description, ok := header["description"]; ok {
+						converted["description"] = description
+					}
+					headers[name] = converted
+				}
+			}
+			responses[code] = response
+		}
+		// limitJSONBody answers these before the gateway sees the body
+		if _, ok := out["requestBody"]; ok {
+			responses["413"] = errorResponse("Request body larger than `HTTP_MAX_BODY_BYTES`.")
+			responses["415"] = errorResponse("Content-Type is not application/json.")
+		}
+	}
+	return out
+}
+
+func errorResponse(description string) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content": map[string]any{
+			"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Error"}},
+		},
+	}
+}
+
+// openAPIRefs returns a copy of v with its swagger definition
+// references pointing at the OpenAPI component schemas.
+func openAPIRefs(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			if ref, ok := value.(string); key == "$ref" && ok {
+				name := strings.TrimPrefix(ref, "#/definitions/")
+				if replacement := generatedSchemas[name]; replacement != "" {
+					name = replacement
+				}
+				out[key] = "#/components/schemas/" + name
+				continue
+			}
+			out[key] = openAPIRefs(value)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, value := range v {
+			out[i] = openAPIRefs(value)
+		}
+		return out
+	}
+	return v
+}
+
+// checkSpecCoverage returns an error naming every registered
+// pattern that the OpenAPI document does not describe.
+func checkSpecCoverage(spec []byte, patterns []string) error {
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return fmt.Errorf("invalid openapi document: %w", err)
+	}
+
+	var missing []string
+	for _, pattern := range patterns {
+		method, path, found := strings.Cut(pattern, " ")
+		if !found {
+			method, path = "", pattern
+		}
+
+		// a subtree pattern such as /v1/ is handed to another
+		// router, which must document at least one route under it
+		if strings.HasSuffix(path, "/") {
+			if !hasPathUnder(doc.Paths, path) {
+				missing = append(missing, pattern)
+			}
+			continue
+		}
+
+		operations, ok := doc.Paths[path]
+		if !ok {
+			missing = append(missing, pattern)
+			continue
+		}
+		// patterns without a method match any
+		// method, so any documented one will do
+		if method == "" {
+			continue
+		}
+		if _, ok := operations[strings.ToLower(method)]; !ok {
+			missing = append(missing, pattern)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("routes missing from the openapi document: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// checkSuccessors returns an error naming every deprecated route
+// whose successor the OpenAPI document does not describe.
+func checkSuccessors(spec []byte, successors map[string]string) error {
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return fmt.Errorf("invalid openapi document: %w", err)
+	}
+
+	var missing []string
+	for pattern, successor := range successors {
+		method, _, _ := strings.Cut(pattern, " ")
+		if _, ok := doc.Paths[successor][strings.ToLower(method)]; !ok {
+			missing = append(missing, pattern+" -> "+successor)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("successors missing from the openapi document: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func hasPathUnder(paths map[string]map[string]json.RawMessage, prefix string) bool {
+	for path := range paths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
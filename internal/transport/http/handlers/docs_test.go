@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type passThrough struct{}
+
+func (passThrough) Wrap(_ string, next http.Handler) http.Handler { return next }
+
+func TestSetupRouterDocumentsEveryRoute(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	_, err := SetupRouter(
+		context.Background(),
+		UserHandler{logger: logger},
+		WalletHandler{logger: logger},
+		http.NotFoundHandler(),
+		passThrough{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenAPIDocumentIncludesGeneratedRoutes(t *testing.T) {
+	spec, err := openAPIDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(spec, []byte("#/definitions/")) {
+		t.Error("the document still references swagger definitions")
+	}
+
+	var doc struct {
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, successor := range apiSuccessors {
+		if _, ok := doc.Paths[successor]; !ok {
+			t.Errorf("%s is missing", successor)
+		}
+	}
+
+	var createUser struct {
+		RequestBody struct {
+			Content map[string]json.RawMessage `json:"content"`
+		} `json:"requestBody"`
+	}
+	if err := json.Unmarshal(doc.Paths["/v1/users"]["post"], &createUser); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := createUser.RequestBody.Content["application/json"]; !ok {
+		t.Error("POST /v1/users has no JSON request body")
+	}
+	if _, ok := doc.Components.Schemas["v1User"]; !ok {
+		t.Error("the generated schemas are missing")
+	}
+	if _, ok := doc.Components.Schemas["rpcStatus"]; ok {
+		t.Error("rpcStatus is documented although errors use the Error envelope")
+	}
+}
+
+func TestCheckSpecCoverage(t *testing.T) {
+	spec := []byte(`{"paths": {"/a": {"get": {}}, "/v1/b": {"post": {}}}}`)
+
+	tests := []struct {
+		pattern string
+		missing bool
+	}{
+		{"GET /a", false},
+		{"/a", false},
+		{"POST /a", true},
+		{"GET /c", true},
+		{"/v1/", false},
+		{"/v2/", true},
+	}
+	for _, tt := range tests {
+		err := checkSpecCoverage(spec, []string{tt.pattern})
+		if missing := err != nil && strings.Contains(err.Error(), tt.pattern); missing != tt.missing {
+			t.Errorf("%s: got err %v, want missing %t", tt.pattern, err, tt.missing)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Oloruntobi1/grey/internal/transport/apierror"
+)
+
+func writeJSON(ctx context.Context, w http.ResponseWriter, logger *slog.Logger, status int, response interface{}) {
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		logger.ErrorContext(
+			ctx,
+			"failed_to_marshal_response",
+			slog.Any("err", err),
+		)
+		http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(responseJSON); err != nil {
+		logger.ErrorContext(
+			ctx,
+			"failed_to_write_response",
+			slog.Any("err", err),
+		)
+	}
+}
+
+// writeServiceError maps err to its Error envelope. Unknown
+// errors are logged and hidden behind a generic 500.
+func writeServiceError(ctx context.Context, w http.ResponseWriter, logger *slog.Logger, err error) {
+	if m, ok := apierror.Find(err); ok {
+		apierror.WriteError(ctx, w, logger, m.HTTPStatus, m.Reason, m.Err.Error())
+		return
+	}
+
+	logger.ErrorContext(
+		ctx,
+		"request_failed",
+		slog.Any("err", err),
+	)
+	apierror.WriteError(ctx, w, logger, http.StatusInternalServerError, "internal_error", "internal server error")
+}
@@ -2,18 +2,128 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strings"
+
+	"github.com/Oloruntobi1/grey/pkg/api"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
+// RouteWrapper wraps the handler of every route, knowing its pattern.
+type RouteWrapper interface {
+	Wrap(pattern string, next http.Handler) http.Handler
+}
+
+// apiSuccessors are the routes generated from the protobuf
+// contract that replace the hand written ones.
+var apiSuccessors = map[string]string{
+	"POST /api/users":                      "/v1/users",
+	"POST /api/wallets":                    "/v1/wallets",
+	"POST /api/create-user":                "/v1/users",
+	"POST /api/create-wallet":              "/v1/wallets",
+	"GET /api/users/{id}":                  "/v1/users/{id}",
+	"PATCH /api/users/{id}":                "/v1/users/{id}",
+	"DELETE /api/users/{id}":               "/v1/users/{id}",
+	"GET /api/wallets/{id}":                "/v1/wallets/{id}",
+	"DELETE /api/wallets/{id}":             "/v1/wallets/{id}",
+	"POST /api/admin/users/{id}/restore":   "/v1/admin/users/{id}/restore",
+	"POST /api/admin/wallets/{id}/restore": "/v1/admin/wallets/{id}/restore",
+	"POST /api/admin/wallets/{id}/status":  "/v1/admin/wallets/{id}/status",
+}
+
 func SetupRouter(
 	ctx context.Context,
 	userHandler UserHandler,
 	walletService WalletHandler,
+	gateway http.Handler,
+	rateLimiter RouteWrapper,
 	// transactionService *service.TransactionService,
-) *http.ServeMux {
+) (http.Handler, error) {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/api/create-user", userHandler.CreateUserHandler(ctx))
-	mux.HandleFunc("/api/create-wallet", walletService.CreateWalletHandler(ctx))
-	// mux.HandleFunc("/process-transaction", processTransactionHandler(transactionService))
-	return mux
+
+	// Every pattern is recorded so we can make sure
+	// openapi.json does not fall behind the router.
+	// The route template labels the request metrics,
+	// the raw path would give them unbounded cardinality.
+	// Requests count against the limit of the pattern
+	// limited, usually the route's own pattern.
+	var patterns []string
+	register := func(pattern, limited string, handler http.Handler) {
+		patterns = append(patterns, pattern)
+		_, route, found := strings.Cut(pattern, " ")
+		if !found {
+			route = pattern
+		}
+		mux.Handle(pattern, otelhttp.WithRouteTag(route, rateLimiter.Wrap(limited, handler)))
+	}
+	handle := func(pattern string, handler http.Handler) {
+		register(pattern, pattern, handler)
+	}
+
+	// Routes under /v1 are generated from the protobuf contract. Each
+	// one is registered on its own so it gets its own rate limit and
+	// route tag, their bodies follow the same rules as the hand
+	// written ones.
+	generated, err := generatedRoutes()
+	if err != nil {
+		return nil, err
+	}
+	v1 := limitJSONBody(userHandler.maxBodyBytes, userHandler.logger, gateway)
+	for _, pattern := range generated {
+		handle(pattern, v1)
+	}
+	handle("GET /v1/swagger.json", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(api.SwaggerJSON)
+	}))
+
+	// Hand written routes kept for existing clients. They are frozen,
+	// new routes are only added to the protobuf contract, and every one
+	// of them points clients at its generated successor, whose rate
+	// limit it shares so it cannot be used to get around it.
+	var orphans []string
+	api := func(pattern string, handler http.Handler) {
+		successor, ok := apiSuccessors[pattern]
+		if !ok {
+			orphans = append(orphans, pattern)
+		}
+		method, _, _ := strings.Cut(pattern, " ")
+		register(pattern, method+" "+successor, deprecated(successor, handler))
+	}
+	api("POST /api/users", userHandler.CreateUserHandler(ctx))
+	api("POST /api/wallets", walletService.CreateWalletHandler(ctx))
+	api("POST /api/create-user", userHandler.CreateUserHandler(ctx))
+	api("POST /api/create-wallet", walletService.CreateWalletHandler(ctx))
+	api("GET /api/users/{id}", userHandler.GetUserHandler(ctx))
+	api("PATCH /api/users/{id}", userHandler.UpdateUserHandler(ctx))
+	api("DELETE /api/users/{id}", userHandler.DeleteUserHandler(ctx))
+	api("GET /api/wallets/{id}", walletService.GetWalletHandler(ctx))
+	api("DELETE /api/wallets/{id}", walletService.DeleteWalletHandler(ctx))
+	api("POST /api/admin/users/{id}/restore", userHandler.RestoreUserHandler(ctx))
+	api("POST /api/admin/wallets/{id}/restore", walletService.RestoreWalletHandler(ctx))
+	api("POST /api/admin/wallets/{id}/status", walletService.ChangeWalletStatusHandler(ctx))
+	// handle("/process-transaction", processTransactionHandler(transactionService))
+
+	spec, err := openAPIDocument()
+	if err != nil {
+		return nil, err
+	}
+	handle("GET /openapi.json", OpenAPIHandler(spec))
+	handle("GET /docs", DocsHandler())
+	handle("GET /docs/redoc.standalone.js", RedocHandler())
+
+	// An undocumented route or a deprecated one without a successor
+	// is a programming error and stops the server from starting.
+	if len(orphans) > 0 {
+		return nil, fmt.Errorf("deprecated routes without a successor: %s", strings.Join(orphans, ", "))
+	}
+	if err := checkSuccessors(spec, apiSuccessors); err != nil {
+		return nil, err
+	}
+	if err := checkSpecCoverage(spec, patterns); err != nil {
+		return nil, err
+	}
+
+	return withRoutingErrors(mux, userHandler.logger), nil
 }
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+// limitedPattern answers every request with the pattern it would be
+// rate limited under instead of calling the route.
+type limitedPattern struct{}
+
+func (limitedPattern) Wrap(pattern string, _ http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Limited", pattern)
+	})
+}
+
+func TestRateLimitPatterns(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	router, err := SetupRouter(
+		context.Background(),
+		UserHandler{logger: logger},
+		WalletHandler{logger: logger},
+		http.NotFoundHandler(),
+		limitedPattern{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := "5b7f2f4e-8f0e-4c65-9b7c-0a3c8f3a1b11"
+	tests := []struct {
+		method, path string
+		want         string
+	}{
+		{"POST", "/v1/users", "POST /v1/users"},
+		{"POST", "/v1/wallets", "POST /v1/wallets"},
+		{"GET", "/v1/users/" + id, "GET /v1/users/{id}"},
+		{"PATCH", "/v1/users/" + id, "PATCH /v1/users/{id}"},
+		{"POST", "/v1/admin/wallets/" + id + "/status", "POST /v1/admin/wallets/{id}/status"},
+		{"POST", "/api/users", "POST /v1/users"},
+		{"POST", "/api/create-user", "POST /v1/users"},
+		{"GET", "/api/users/" + id, "GET /v1/users/{id}"},
+		{"GET", "/openapi.json", "GET /openapi.json"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if got := w.Header().Get("X-Limited"); got != tt.want {
+			t.Errorf("%s %s: limited as %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRouteTags(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	router, err := SetupRouter(
+		context.Background(),
+		UserHandler{logger: logger},
+		WalletHandler{logger: logger},
+		http.NotFoundHandler(),
+		passThrough{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := "5b7f2f4e-8f0e-4c65-9b7c-0a3c8f3a1b11"
+	tests := []struct {
+		method, path string
+		want         string
+	}{
+		{"POST", "/v1/users", "/v1/users"},
+		{"GET", "/v1/users/" + id, "/v1/users/{id}"},
+		{"DELETE", "/v1/wallets/" + id, "/v1/wallets/{id}"},
+		{"POST", "/v1/admin/users/" + id + "/restore", "/v1/admin/users/{id}/restore"},
+		{"GET", "/v1/swagger.json", "/v1/swagger.json"},
+	}
+	recorder := tracetest.NewSpanRecorder()
+	traced := otelhttp.NewHandler(router, "test",
+		otelhttp.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+	)
+	for _, tt := range tests {
+		traced.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+
+		spans := recorder.Ended()
+		var route string
+		for _, attr := range spans[len(spans)-1].Attributes() {
+			if attr.Key == semconv.HTTPRouteKey {
+				route = attr.Value.AsString()
+			}
+		}
+		if route != tt.want {
+			t.Errorf("%s %s: http.route = %q, want %q", tt.method, tt.path, route, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Oloruntobi1/grey/internal/transport/apierror"
+	"github.com/Oloruntobi1/grey/pkg/api"
+)
+
+// routingMethods are probed against the mux to build the Allow header.
+var routingMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// withRoutingErrors answers requests that match no pattern with a 404,
+// or a 405 and an Allow header when the path exists for other methods,
+// both in the Error envelope instead of the ServeMux plain text bodies.
+func withRoutingErrors(mux *http.ServeMux, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		allowed := allowedMethods(mux, r)
+		if len(allowed) == 0 {
+			apierror.WriteError(ctx, w, logger, http.StatusNotFound, "route_not_found", "no route matches "+r.URL.Path)
+			return
+		}
+
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		apierror.WriteError(ctx, w, logger, http.StatusMethodNotAllowed, "method_not_allowed",
+			r.Method+" is not allowed on "+r.URL.Path)
+	})
+}
+
+func allowedMethods(mux *http.ServeMux, r *http.Request) []string {
+	var allowed []string
+	for _, method := range routingMethods {
+		probe := *r
+		probe.Method = method
+		if _, pattern := mux.Handler(&probe); pattern != "" {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// deprecated marks responses of an old route and points clients
+// at its replacement, see RFC 9745 and RFC 8288. The {wildcards}
+// of successor are filled from the request path.
+func deprecated(successor string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		segments := strings.Split(successor, "/")
+		for i, segment := range segments {
+			if name, ok := strings.CutPrefix(segment, "{"); ok {
+				segments[i] = r.PathValue(strings.TrimSuffix(name, "}"))
+			}
+		}
+
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+strings.Join(segments, "/")+`>; rel="successor-version"`)
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// generatedRoutes returns a ServeMux pattern for every operation of
+// the swagger document generated from the protobuf contract.
+func generatedRoutes() ([]string, error) {
+	var swagger struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(api.SwaggerJSON, &swagger); err != nil {
+		return nil, fmt.Errorf("invalid swagger document: %w", err)
+	}
+
+	var patterns []string
+	for path, operations := range swagger.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+			patterns = append(patterns, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(patterns)
+	return patterns, nil
+}
@@ -3,11 +3,16 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/Oloruntobi1/grey/internal/models"
+	"github.com/Oloruntobi1/grey/internal/transport/apierror"
+	"github.com/Oloruntobi1/grey/internal/transport/etag"
 	"github.com/Oloruntobi1/grey/internal/transport/http/domains/users"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -16,14 +21,18 @@ type UserHandler struct {
 	svc    users.UserService
 	logger *slog.Logger
 
+	// maxBodyBytes caps the size of request bodies.
+	maxBodyBytes int64
+
 	tracer trace.Tracer
 }
 
-func NewUserHandler(svc users.UserService, logger *slog.Logger) *UserHandler {
+func NewUserHandler(svc users.UserService, logger *slog.Logger, maxBodyBytes int64) *UserHandler {
 	return &UserHandler{
-		svc:    svc,
-		logger: logger,
-		tracer: otel.Tracer("userHandler"),
+		svc:          svc,
+		logger:       logger,
+		maxBodyBytes: maxBodyBytes,
+		tracer:       otel.Tracer("userHandler"),
 	}
 }
 
@@ -32,13 +41,39 @@ type createUserRequest struct {
 	Email string `json:"email"`
 }
 
+type updateUserRequest struct {
+	Name  *string `json:"name"`
+	Email *string `json:"email"`
+}
+
+type userResponse struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+func newUserResponse(user *models.User) *userResponse {
+	resp := &userResponse{
+		ID:        user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt,
+	}
+	if !user.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &user.UpdatedAt
+	}
+	return resp
+}
+
 func (h *UserHandler) CreateUserHandler(ctx context.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, span := h.tracer.Start(r.Context(), "createUserHandler")
 		defer span.End()
 		var request createUserRequest
-		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		if err := decodeJSON(w, r, h.maxBodyBytes, &request); err != nil {
+			writeDecodeError(ctx, w, h.logger, err)
 			return
 		}
 		id, err := h.svc.CreateUser(ctx, &models.User{
@@ -46,7 +81,7 @@ func (h *UserHandler) CreateUserHandler(ctx context.Context) http.HandlerFunc {
 			Email: request.Email,
 		})
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeServiceError(ctx, w, h.logger, err)
 			return
 		}
 		response := ResponseWithID(ctx, id)
@@ -72,3 +107,103 @@ func (h *UserHandler) CreateUserHandler(ctx context.Context) http.HandlerFunc {
 		}
 	}
 }
+
+func (h *UserHandler) GetUserHandler(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := h.tracer.Start(r.Context(), "getUserHandler")
+		defer span.End()
+		id := r.PathValue("id")
+		if _, err := uuid.Parse(id); err != nil {
+			apierror.WriteError(ctx, w, h.logger, http.StatusBadRequest, "invalid_id", "id must be a valid uuid")
+			return
+		}
+		user, err := h.svc.GetUser(ctx, id)
+		if err != nil {
+			writeServiceError(ctx, w, h.logger, err)
+			return
+		}
+		w.Header().Set("ETag", etag.FromVersion(user.Version))
+		writeJSON(ctx, w, h.logger, http.StatusOK, ResponseWithObj(ctx, newUserResponse(user)))
+	}
+}
+
+// UpdateUserHandler applies a partial update. Sending the ETag from a
+// previous read in If-Match makes the update fail with 412 when the
+// user was changed by someone else in the meantime.
+func (h *UserHandler) UpdateUserHandler(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := h.tracer.Start(r.Context(), "updateUserHandler")
+		defer span.End()
+		id := r.PathValue("id")
+		if _, err := uuid.Parse(id); err != nil {
+			apierror.WriteError(ctx, w, h.logger, http.StatusBadRequest, "invalid_id", "id must be a valid uuid")
+			return
+		}
+		expectedVersion, err := etag.ParseIfMatch(r.Header.Get("If-Match"))
+		if errors.Is(err, etag.ErrInvalidIfMatch) {
+			apierror.WriteError(ctx, w, h.logger, http.StatusBadRequest, "invalid_if_match", err.Error())
+			return
+		}
+		if err != nil {
+			writeServiceError(ctx, w, h.logger, err)
+			return
+		}
+		var request updateUserRequest
+		if err := decodeJSON(w, r, h.maxBodyBytes, &request); err != nil {
+			writeDecodeError(ctx, w, h.logger, err)
+			return
+		}
+		if request.Name == nil && request.Email == nil {
+			apierror.WriteError(ctx, w, h.logger, http.StatusBadRequest, "empty_update", "at least one of name or email is required")
+			return
+		}
+		if (request.Name != nil && *request.Name == "") || (request.Email != nil && *request.Email == "") {
+			apierror.WriteError(ctx, w, h.logger, http.StatusBadRequest, "invalid_body", "name and email cannot be empty")
+			return
+		}
+		user, err := h.svc.UpdateUser(ctx, id, &models.UserUpdate{
+			Name:  request.Name,
+			Email: request.Email,
+		}, expectedVersion)
+		if err != nil {
+			writeServiceError(ctx, w, h.logger, err)
+			return
+		}
+		w.Header().Set("ETag", etag.FromVersion(user.Version))
+		writeJSON(ctx, w, h.logger, http.StatusOK, ResponseWithObj(ctx, newUserResponse(user)))
+	}
+}
+
+func (h *UserHandler) DeleteUserHandler(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := h.tracer.Start(r.Context(), "deleteUserHandler")
+		defer span.End()
+		id := r.PathValue("id")
+		if _, err := uuid.Parse(id); err != nil {
+			apierror.WriteError(ctx, w, h.logger, http.StatusBadRequest, "invalid_id", "id must be a valid uuid")
+			return
+		}
+		if err := h.svc.DeleteUser(ctx, id); err != nil {
+			writeServiceError(ctx, w, h.logger, err)
+			return
+		}
+		writeJSON(ctx, w, h.logger, http.StatusOK, ResponseWithID(ctx, id))
+	}
+}
+
+func (h *UserHandler) RestoreUserHandler(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := h.tracer.Start(r.Context(), "restoreUserHandler")
+		defer span.End()
+		id := r.PathValue("id")
+		if _, err := uuid.Parse(id); err != nil {
+			apierror.WriteError(ctx, w, h.logger, http.StatusBadRequest, "invalid_id", "id must be a valid uuid")
+			return
+		}
+		if err := h.svc.RestoreUser(ctx, id); err != nil {
+			writeServiceError(ctx, w, h.logger, err)
+			return
+		}
+		writeJSON(ctx, w, h.logger, http.StatusOK, ResponseWithID(ctx, id))
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"net/http"
 
 	"github.com/Oloruntobi1/grey/internal/models"
+	"github.com/Oloruntobi1/grey/internal/transport/apierror"
 	"github.com/Oloruntobi1/grey/internal/transport/http/domains/wallets"
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
@@ -17,14 +19,18 @@ type WalletHandler struct {
 	svc    wallets.WalletService
 	logger *slog.Logger
 
+	// maxBodyBytes caps the size of request bodies.
+	maxBodyBytes int64
+
 	tracer trace.Tracer
 }
 
-func NewWalletHandler(svc wallets.WalletService, logger *slog.Logger) *WalletHandler {
+func NewWalletHandler(svc wallets.WalletService, logger *slog.Logger, maxBodyBytes int64) *WalletHandler {
 	return &WalletHandler{
-		svc:    svc,
-		logger: logger,
-		tracer: otel.Tracer("userHandler"),
+		svc:          svc,
+		logger:       logger,
+		maxBodyBytes: maxBodyBytes,
+		tracer:       otel.Tracer("userHandler"),
 	}
 }
 
@@ -33,13 +39,25 @@ type createWalletRequest struct {
 	InitialBalance decimal.Decimal `json:"initial_balance"`
 }
 
+type changeWalletStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
 func (h *WalletHandler) CreateWalletHandler(ctx context.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, span := h.tracer.Start(r.Context(), "createWalletHandler")
 		defer span.End()
 		var request createWalletRequest
-		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		if err := decodeJSON(w, r, h.maxBodyBytes, &request); err != nil {
+			writeDecodeError(ctx, w, h.logger, err)
+			return
+		}
+		if _, err := uuid.Parse(request.UserID); err != nil {
+			writeDecodeError(ctx, w, h.logger, invalidFields(apierror.ErrorField{
+				Name:    apierror.String("user_id"),
+				Message: apierror.String("must be a valid uuid"),
+			}))
 			return
 		}
 		id, err := h.svc.CreateWallet(ctx, &models.Wallet{
@@ -47,7 +65,7 @@ func (h *WalletHandler) CreateWalletHandler(ctx context.Context) http.HandlerFun
 			Balance: request.InitialBalance,
 		})
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeServiceError(ctx, w, h.logger, err)
 			return
 		}
 		response := ResponseWithID(ctx, id)
@@ -73,3 +91,81 @@ func (h *WalletHandler) CreateWalletHandler(ctx context.Context) http.HandlerFun
 		}
 	}
 }
+
+func (h *WalletHandler) GetWalletHandler(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := h.tracer.Start(r.Context(), "getWalletHandler")
+		defer span.End()
+		id := r.PathValue("id")
+		if _, err := uuid.Parse(id); err != nil {
+			apierror.WriteError(ctx, w, h.logger, http.StatusBadRequest, "invalid_id", "id must be a valid uuid")
+			return
+		}
+		wallet, err := h.svc.GetWallet(ctx, id)
+		if err != nil {
+			writeServiceError(ctx, w, h.logger, err)
+			return
+		}
+		writeJSON(ctx, w, h.logger, http.StatusOK, ResponseWithObj(ctx, wallet))
+	}
+}
+
+// ChangeWalletStatusHandler lets an admin freeze, block, unblock or close
+// a wallet. The reason is stored on the wallet and, through the update
+// trigger, in wallets_logs alongside the previous status.
+func (h *WalletHandler) ChangeWalletStatusHandler(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := h.tracer.Start(r.Context(), "changeWalletStatusHandler")
+		defer span.End()
+		id := r.PathValue("id")
+		if _, err := uuid.Parse(id); err != nil {
+			apierror.WriteError(ctx, w, h.logger, http.StatusBadRequest, "invalid_id", "id must be a valid uuid")
+			return
+		}
+		var request changeWalletStatusRequest
+		if err := decodeJSON(w, r, h.maxBodyBytes, &request); err != nil {
+			writeDecodeError(ctx, w, h.logger, err)
+			return
+		}
+		wallet, err := h.svc.ChangeWalletStatus(ctx, id, models.WalletStatus(request.Status), request.Reason)
+		if err != nil {
+			writeServiceError(ctx, w, h.logger, err)
+			return
+		}
+		writeJSON(ctx, w, h.logger, http.StatusOK, ResponseWithObj(ctx, wallet))
+	}
+}
+
+func (h *WalletHandler) DeleteWalletHandler(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := h.tracer.Start(r.Context(), "deleteWalletHandler")
+		defer span.End()
+		id := r.PathValue("id")
+		if _, err := uuid.Parse(id); err != nil {
+			apierror.WriteError(ctx, w, h.logger, http.StatusBadRequest, "invalid_id", "id must be a valid uuid")
+			return
+		}
+		if err := h.svc.DeleteWallet(ctx, id); err != nil {
+			writeServiceError(ctx, w, h.logger, err)
+			return
+		}
+		writeJSON(ctx, w, h.logger, http.StatusOK, ResponseWithID(ctx, id))
+	}
+}
+
+func (h *WalletHandler) RestoreWalletHandler(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := h.tracer.Start(r.Context(), "restoreWalletHandler")
+		defer span.End()
+		id := r.PathValue("id")
+		if _, err := uuid.Parse(id); err != nil {
+			apierror.WriteError(ctx, w, h.logger, http.StatusBadRequest, "invalid_id", "id must be a valid uuid")
+			return
+		}
+		if err := h.svc.RestoreWallet(ctx, id); err != nil {
+			writeServiceError(ctx, w, h.logger, err)
+			return
+		}
+		writeJSON(ctx, w, h.logger, http.StatusOK, ResponseWithID(ctx, id))
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Oloruntobi1/grey/internal/auth"
+)
+
+// ClientCertPrincipal authenticates requests that presented a verified
+// client certificate as a service principal. principals maps certificate
+// common names to service names, unmapped names are used as they are.
+func ClientCertPrincipal(principals map[string]string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// VerifiedChains is only set when the chain checked out against the client CAs
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cn := r.TLS.VerifiedChains[0][0].Subject.CommonName
+		name, ok := principals[cn]
+		if !ok {
+			name = cn
+		}
+		if name == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := auth.WithPrincipal(r.Context(), auth.Principal{
+			Kind: auth.PrincipalService,
+			Name: name,
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
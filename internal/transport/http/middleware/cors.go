@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Oloruntobi1/grey/internal/transport/apierror"
+)
+
+// exposedHeaders are the response headers browser clients need to read.
+var exposedHeaders = []string{
+	"ETag",
+	"Allow",
+	"Retry-After",
+	"RateLimit-Limit",
+	"RateLimit-Remaining",
+	"RateLimit-Reset",
+	"Deprecation",
+	"Link",
+}
+
+type CORSOptions struct {
+	// AllowedOrigins may contain "*" to allow every origin.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration
+}
+
+// CORS lets browser clients on the allowed origins call the API.
+type CORS struct {
+	opts   CORSOptions
+	logger *slog.Logger
+
+	anyOrigin bool
+	methods   string
+	headers   string
+}
+
+func NewCORS(opts CORSOptions, logger *slog.Logger) (*CORS, error) {
+	anyOrigin := slices.Contains(opts.AllowedOrigins, "*")
+	// any site could otherwise make requests carrying the user's cookies
+	if anyOrigin && opts.AllowCredentials {
+		return nil, errors.New("cors: credentials cannot be allowed for every origin")
+	}
+
+	return &CORS{
+		opts:      opts,
+		logger:    logger,
+		anyOrigin: anyOrigin,
+		methods:   strings.ToUpper(strings.Join(opts.AllowedMethods, ", ")),
+		headers:   strings.Join(opts.AllowedHeaders, ", "),
+	}, nil
+}
+
+func (c *CORS) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// the response differs per origin, shared caches must know
+		w.Header().Add("Vary", "Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		if !c.allowedOrigin(origin) {
+			if preflight {
+				apierror.WriteError(r.Context(), w, c.logger, http.StatusForbidden, "cors_origin_not_allowed", "origin "+origin+" is not allowed")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		c.setOrigin(w, origin)
+
+		if !preflight {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(exposedHeaders, ", "))
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		w.Header().Set("Access-Control-Allow-Methods", c.methods)
+		w.Header().Set("Access-Control-Allow-Headers", c.headers)
+		if c.opts.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.opts.MaxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (c *CORS) allowedOrigin(origin string) bool {
+	return c.anyOrigin || slices.Contains(c.opts.AllowedOrigins, origin)
+}
+
+func (c *CORS) setOrigin(w http.ResponseWriter, origin string) {
+	if c.anyOrigin {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if c.opts.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
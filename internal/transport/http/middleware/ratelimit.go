@@ -0,0 +1,150 @@
+// Package middleware holds the http.Handler wrappers shared by the routes.
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Oloruntobi1/grey/internal/auth"
+	"github.com/Oloruntobi1/grey/internal/ratelimit"
+	"github.com/Oloruntobi1/grey/internal/transport/apierror"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// RateLimiter limits every route separately for every principal: the
+// authenticated service, an API key named in the principal limits and
+// the client IP address otherwise.
+type RateLimiter struct {
+	store  ratelimit.Store
+	logger *slog.Logger
+
+	defaultLimit ratelimit.Limit
+	// routeLimits are keyed by ServeMux pattern.
+	routeLimits map[string]ratelimit.Limit
+	// principalLimits are keyed by service:<name>, key:<api key> or
+	// ip:<address> and take precedence over the route limits.
+	principalLimits map[string]ratelimit.Limit
+
+	// trustProxy takes the client IP from the last X-Forwarded-For
+	// entry, the one added by the proxy in front of the server.
+	trustProxy bool
+}
+
+func NewRateLimiter(
+	store ratelimit.Store,
+	logger *slog.Logger,
+	defaultLimit ratelimit.Limit,
+	routeLimits map[string]ratelimit.Limit,
+	principalLimits map[string]ratelimit.Limit,
+	trustProxy bool,
+) *RateLimiter {
+	return &RateLimiter{
+		store:           store,
+		logger:          logger,
+		defaultLimit:    defaultLimit,
+		routeLimits:     routeLimits,
+		principalLimits: principalLimits,
+		trustProxy:      trustProxy,
+	}
+}
+
+// Wrap limits the handler registered for pattern. A nil
+// RateLimiter leaves the handler unlimited.
+func (l *RateLimiter) Wrap(pattern string, next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		principal, bucketID := l.principal(r)
+
+		limit, ok := l.principalLimits[principal]
+		if !ok {
+			limit, ok = l.routeLimits[pattern]
+		}
+		if !ok {
+			limit = l.defaultLimit
+		}
+
+		result, err := l.store.Take(ctx, pattern+"|"+bucketID, limit)
+		if err != nil {
+			// an unavailable store should not take the API down with it
+			l.logger.ErrorContext(
+				ctx,
+				"rate_limit_store_failed",
+				slog.Any("err", err),
+			)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("RateLimit-Reset", ceilSeconds(result.Reset))
+
+		if !result.Allowed {
+			w.Header().Set("Retry-After", ceilSeconds(result.RetryAfter))
+			apierror.WriteError(r.Context(), w, l.logger, http.StatusTooManyRequests, "rate_limited", "too many requests, retry later")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// principal returns the name used to look up limits and the id used in
+// bucket keys. Only API keys given a limit are known, any other key is
+// ignored so callers cannot get a fresh bucket by making one up. Keys
+// are hashed so they are not stored in the clear.
+func (l *RateLimiter) principal(r *http.Request) (string, string) {
+	if p, ok := auth.PrincipalFrom(r.Context()); ok {
+		return p.String(), p.String()
+	}
+
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		if _, ok := l.principalLimits["key:"+key]; ok {
+			sum := sha256.Sum256([]byte(key))
+			return "key:" + key, "key:" + hex.EncodeToString(sum[:16])
+		}
+	}
+
+	ip := clientIP(r, l.trustProxy)
+	return "ip:" + ip, "ip:" + ip
+}
+
+// clientIP returns the address of the caller. Behind a trusted proxy
+// that is the last X-Forwarded-For entry, the earlier ones are sent by
+// the caller and could be anything.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			last := values[len(values)-1]
+			if i := strings.LastIndex(last, ","); i >= 0 {
+				last = last[i+1:]
+			}
+			if ip := strings.TrimSpace(last); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ceilSeconds rounds up so clients never retry too early.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
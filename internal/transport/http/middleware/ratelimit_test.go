@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		forwarded  []string
+		trustProxy bool
+		want       string
+	}{
+		{name: "remote address", want: "192.0.2.1"},
+		{name: "forwarded ignored without a proxy", forwarded: []string{"198.51.100.7"}, want: "192.0.2.1"},
+		{name: "proxy entry", forwarded: []string{"198.51.100.7"}, trustProxy: true, want: "198.51.100.7"},
+		{name: "caller entries are skipped", forwarded: []string{"203.0.113.9, 198.51.100.7"}, trustProxy: true, want: "198.51.100.7"},
+		{name: "last header wins", forwarded: []string{"203.0.113.9", "198.51.100.7"}, trustProxy: true, want: "198.51.100.7"},
+		{name: "empty entry", forwarded: []string{"203.0.113.9, "}, trustProxy: true, want: "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		for _, v := range tt.forwarded {
+			r.Header.Add("X-Forwarded-For", v)
+		}
+		if got := clientIP(r, tt.trustProxy); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
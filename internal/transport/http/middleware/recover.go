@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Oloruntobi1/grey/internal/transport/apierror"
+	"github.com/Oloruntobi1/grey/pkg/redact"
+)
+
+// Recoverer turns panics in handlers into a 500 Error envelope carrying
+// the trace ID, so one bad request cannot take the server down and the
+// client can hand the trace ID to support.
+type Recoverer struct {
+	logger *slog.Logger
+	panics metric.Int64Counter
+}
+
+func NewRecoverer(logger *slog.Logger) (*Recoverer, error) {
+	panics, err := otel.Meter("httpServer").Int64Counter(
+		"http.server.panics",
+		metric.WithDescription("Number of panics recovered while serving HTTP requests."),
+		metric.WithUnit("{panic}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recoverer{
+		logger: logger,
+		panics: panics,
+	}, nil
+}
+
+// Handler must run inside the request span for the
+// trace ID to reach the response and the logs.
+func (rc *Recoverer) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w}
+
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			// net/http uses this panic to abort a response on purpose
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+
+			ctx := r.Context()
+			err := fmt.Errorf("panic: %v", v)
+			stack := string(debug.Stack())
+
+			// the panic value may carry personal data, such as
+			// the input that made a handler index out of range
+			redact.RecordError(trace.SpanFromContext(ctx), err, semconv.ExceptionStacktrace(stack))
+
+			// the OtelHandler adds this record to the span as an event
+			rc.logger.ErrorContext(
+				ctx,
+				"panic_recovered",
+				slog.Any("err", err),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("stack", stack),
+			)
+
+			rc.panics.Add(ctx, 1, metric.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+			))
+
+			// too late for an envelope once the handler started answering
+			if rw.wroteHeader {
+				return
+			}
+			apierror.WriteError(r.Context(), w, rc.logger, http.StatusInternalServerError, "internal_error", "internal server error")
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// responseWriter remembers whether the status line was sent.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/Oloruntobi1/grey/pkg/redact"
+)
+
+func TestRecovererRedactsPanic(t *testing.T) {
+	policy, err := redact.New(redact.Options{Mode: redact.Mask, Patterns: []string{"email"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	redact.SetDefault(policy)
+	defer redact.SetDefault(nil)
+
+	rc, err := NewRecoverer(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	handler := rc.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("no user ada@example.com")
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx, span := tracer.Start(r.Context(), "request")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r.WithContext(ctx))
+	span.End()
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want 500", w.Code)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	got := spans[0]
+	if got.Status().Code != codes.Error || strings.Contains(got.Status().Description, "ada@") {
+		t.Errorf("got status %+v", got.Status())
+	}
+	if len(got.Events()) != 1 {
+		t.Fatalf("got %d events, want 1", len(got.Events()))
+	}
+	var stack bool
+	for _, kv := range got.Events()[0].Attributes {
+		if strings.Contains(kv.Value.Emit(), "ada@") {
+			t.Errorf("%s is not redacted: %s", kv.Key, kv.Value.Emit())
+		}
+		if kv.Key == "exception.stacktrace" {
+			stack = true
+		}
+	}
+	if !stack {
+		t.Error("no stack trace recorded")
+	}
+}
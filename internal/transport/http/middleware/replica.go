@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Oloruntobi1/grey/internal/db/replica"
+)
+
+// ReadYourWrites starts a replica session for every request, so reads
+// made after a write in the same request are served by the primary.
+func ReadYourWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(replica.WithSession(r.Context())))
+	})
+}
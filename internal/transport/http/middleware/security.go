@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// APICSP is sent with every JSON response, nothing may be loaded
+// from it and it may not be framed.
+const APICSP = "default-src 'none'; frame-ancestors 'none'; base-uri 'none'; form-action 'none'"
+
+// DocsCSP lets the docs page load Redoc and the spec
+// from this server. Redoc injects its styles and searches in a worker.
+const DocsCSP = "default-src 'none'; " +
+	"script-src 'self'; " +
+	"style-src 'unsafe-inline'; " +
+	"img-src 'self' data:; " +
+	"font-src 'self' data:; " +
+	"connect-src 'self'; " +
+	"worker-src blob:; " +
+	"frame-ancestors 'none'; base-uri 'none'; form-action 'none'"
+
+type SecurityHeadersOptions struct {
+	// HSTSMaxAge disables Strict-Transport-Security when zero.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	// PathCSP replaces APICSP for the given request paths.
+	PathCSP map[string]string
+}
+
+// SecurityHeaders sets the headers that keep browsers from sniffing,
+// framing or downgrading responses of the API.
+func SecurityHeaders(opts SecurityHeadersOptions, next http.Handler) http.Handler {
+	var hsts string
+	if opts.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(opts.HSTSMaxAge.Seconds()))
+		if opts.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		if hsts != "" {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "no-referrer")
+
+		csp, ok := opts.PathCSP[r.URL.Path]
+		if !ok {
+			csp = APICSP
+		}
+		h.Set("Content-Security-Policy", csp)
+
+		next.ServeHTTP(w, r)
+	})
+}
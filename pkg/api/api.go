@@ -0,0 +1,11 @@
+// Package api holds the code and docs generated from the protobuf
+// contracts in proto/. Regenerate everything with `make proto`.
+package api
+
+import _ "embed"
+
+// SwaggerJSON is the OpenAPI v2 document generated for the REST
+// routes declared with google.api.http annotations.
+//
+//go:embed grey.swagger.json
+var SwaggerJSON []byte
@@ -0,0 +1,262 @@
+// Package client is a typed Go client for the grey wallet REST API.
+//
+// It calls the /v1 routes. Every request carries the caller's trace
+// context and is retried with exponential backoff on network errors and
+// 429/5xx responses. Creates and updates carry an Idempotency-Key that
+// is generated once per call and sent again on every retry.
+//
+// The server does not honour Idempotency-Key yet: a create or update
+// that failed with a 5xx or a broken connection after the server acted
+// on it is applied again when retried. Use WithRetries(0) where that is
+// not acceptable.
+//
+// Failed calls return an *APIError that can be compared with the
+// sentinels in this package using errors.Is.
+//
+// Restoring users and wallets and changing a wallet's status are admin
+// calls, they need the certificate of an admin service, set through the
+// TLS config of WithHTTPClient.
+//
+// Users and wallets are covered. Transfers are not, the server does not
+// implement them yet and POST /v1/transfers answers 501.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+
+	maxRetries  int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+
+	Users   *UsersService
+	Wallets *WalletsService
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces the underlying HTTP client, nil keeps the
+// default. Its transport is wrapped so outgoing requests still carry
+// the trace context.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithRetries sets how many times a failed request is retried.
+func WithRetries(maxRetries int) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+	}
+}
+
+// WithBackoff sets the first retry delay and the cap it doubles up to.
+func WithBackoff(base, max time.Duration) Option {
+	return func(c *Client) {
+		c.baseBackoff = base
+		c.maxBackoff = max
+	}
+}
+
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url: %w", err)
+	}
+
+	c := &Client{
+		baseURL:     u,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		maxRetries:  3,
+		baseBackoff: 100 * time.Millisecond,
+		maxBackoff:  5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// Copy the client so we do not mutate one owned by the caller.
+	httpClient := *c.httpClient
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	httpClient.Transport = otelhttp.NewTransport(transport)
+	c.httpClient = &httpClient
+
+	c.Users = &UsersService{client: c}
+	c.Wallets = &WalletsService{client: c}
+
+	return c, nil
+}
+
+// request describes a single API call.
+type request struct {
+	method  string
+	path    string
+	body    interface{}
+	headers http.Header
+}
+
+type meta struct {
+	TraceID *string `json:"trace_id"`
+}
+
+type idData struct {
+	ID string `json:"id"`
+}
+
+// do sends req, retrying the failures retryable allows, and decodes the
+// response into out when it is set.
+func (c *Client) do(ctx context.Context, req request, out interface{}) (http.Header, error) {
+	var payload []byte
+	if req.body != nil {
+		var err error
+		payload, err = json.Marshal(req.body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	headers := req.headers.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	// one key for every attempt, so they are all the same operation
+	if !idempotent(req.method) && headers.Get("Idempotency-Key") == "" {
+		headers.Set("Idempotency-Key", uuid.NewString())
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, req, headers, payload)
+		if !retryable(resp, err) || attempt >= c.maxRetries || ctx.Err() != nil {
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+			return resp.Header, decodeResponse(resp, out)
+		}
+
+		delay := c.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = retryAfter
+			}
+			// drain so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, req request, headers http.Header, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, c.baseURL.String()+req.path, body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header = headers.Clone()
+	httpReq.Header.Set("Accept", "application/json")
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	return c.httpClient.Do(httpReq)
+}
+
+// backoff doubles the delay per attempt and adds up to 50% jitter.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.baseBackoff << attempt
+	if delay <= 0 || delay > c.maxBackoff {
+		delay = c.maxBackoff
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// retryable reports whether a failed attempt may be sent again. Every
+// request is either idempotent or carries an Idempotency-Key, so any
+// network error, 429 or 5xx is.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// idempotent reports whether sending a request twice has the
+// same effect as sending it once, without an Idempotency-Key.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter supports both the seconds and HTTP date forms.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return time.Until(at), true
+	}
+	return 0, false
+}
+
+func decodeResponse(resp *http.Response, out interface{}) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return newAPIError(resp.StatusCode, body)
+	}
+
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func pathEscape(id string) string {
+	return url.PathEscape(id)
+}
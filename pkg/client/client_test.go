@@ -0,0 +1,246 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// server answers the i-th request with responses[i], repeating the
+// last one, and records the requests it received.
+type server struct {
+	mu        sync.Mutex
+	responses []func(w http.ResponseWriter)
+	requests  []*http.Request
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	i := min(len(s.requests), len(s.responses)-1)
+	s.requests = append(s.requests, r)
+	s.mu.Unlock()
+	s.responses[i](w)
+}
+
+func (s *server) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+func status(code int, headers ...string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		for i := 0; i+1 < len(headers); i += 2 {
+			w.Header().Set(headers[i], headers[i+1])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		if code >= http.StatusBadRequest {
+			_, _ = w.Write([]byte(`{"error": {"code": "test", "message": "failed"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "1", "user": {"id": "1", "version": "2"}}`))
+	}
+}
+
+func newTestClient(t *testing.T, s *server, opts ...Option) *Client {
+	t.Helper()
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+
+	c, err := New(ts.URL, append([]Option{WithBackoff(time.Millisecond, time.Millisecond)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []func(w http.ResponseWriter)
+		call      func(c *Client) error
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			name:      "get is retried on 5xx",
+			responses: []func(http.ResponseWriter){status(503), status(502), status(200)},
+			call:      func(c *Client) error { _, err := c.Users.Get(context.Background(), "1"); return err },
+			wantCalls: 3,
+		},
+		{
+			name:      "get gives up after the retries",
+			responses: []func(http.ResponseWriter){status(500)},
+			call:      func(c *Client) error { _, err := c.Users.Get(context.Background(), "1"); return err },
+			wantCalls: 4,
+			wantErr:   ErrInternal,
+		},
+		{
+			name:      "post is retried on 5xx",
+			responses: []func(http.ResponseWriter){status(500), status(201)},
+			call: func(c *Client) error {
+				_, err := c.Users.Create(context.Background(), CreateUserRequest{Name: "a", Email: "a@b.c"})
+				return err
+			},
+			wantCalls: 2,
+		},
+		{
+			name:      "post is retried on 429",
+			responses: []func(http.ResponseWriter){status(429, "Retry-After", "0"), status(201)},
+			call: func(c *Client) error {
+				_, err := c.Users.Create(context.Background(), CreateUserRequest{Name: "a", Email: "a@b.c"})
+				return err
+			},
+			wantCalls: 2,
+		},
+		{
+			name:      "4xx is not retried",
+			responses: []func(http.ResponseWriter){status(409), status(201)},
+			call: func(c *Client) error {
+				_, err := c.Users.Create(context.Background(), CreateUserRequest{Name: "a", Email: "a@b.c"})
+				return err
+			},
+			wantCalls: 1,
+			wantErr:   ErrConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{responses: tt.responses}
+			err := tt.call(newTestClient(t, s))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got err %v, want %v", err, tt.wantErr)
+			}
+			if got := s.count(); got != tt.wantCalls {
+				t.Errorf("got %d requests, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryAfterOverridesBackoff(t *testing.T) {
+	s := &server{responses: []func(http.ResponseWriter){status(429, "Retry-After", "0"), status(200)}}
+	c := newTestClient(t, s, WithBackoff(time.Hour, time.Hour))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Users.Get(context.Background(), "1")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the retry waited for the backoff instead of Retry-After")
+	}
+}
+
+func TestIdempotencyKeyIsReusedOnRetries(t *testing.T) {
+	s := &server{responses: []func(http.ResponseWriter){status(500), status(503), status(201)}}
+	c := newTestClient(t, s)
+
+	ctx := context.Background()
+	if _, err := c.Users.Create(ctx, CreateUserRequest{Name: "a", Email: "a@b.c"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Users.Get(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Users.Create(ctx, CreateUserRequest{Name: "b", Email: "b@b.c"}); err != nil {
+		t.Fatal(err)
+	}
+
+	key := s.requests[0].Header.Get("Idempotency-Key")
+	if key == "" {
+		t.Fatal("the create has no Idempotency-Key")
+	}
+	for _, r := range s.requests[1:3] {
+		if got := r.Header.Get("Idempotency-Key"); got != key {
+			t.Errorf("retry sent key %q, want %q", got, key)
+		}
+	}
+	if got := s.requests[3].Header.Get("Idempotency-Key"); got != "" {
+		t.Errorf("the get sent key %q", got)
+	}
+	if got := s.requests[4].Header.Get("Idempotency-Key"); got == "" || got == key {
+		t.Errorf("the second create sent key %q, want a new one", got)
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	s := &server{responses: []func(http.ResponseWriter){status(503)}}
+	c := newTestClient(t, s, WithBackoff(time.Hour, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.Users.Get(ctx, "1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got err %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("returned after %s", elapsed)
+	}
+	if got := s.count(); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Users.Get(canceled, "1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("got err %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	err := &APIError{StatusCode: http.StatusConflict, Code: "wallet_frozen"}
+
+	tests := []struct {
+		target error
+		want   bool
+	}{
+		{ErrWalletFrozen, true},
+		{ErrConflict, true},
+		{ErrWalletClosed, false},
+		{ErrNotFound, false},
+		{&APIError{}, false},
+		{errors.New("wallet_frozen"), false},
+	}
+	for _, tt := range tests {
+		if got := errors.Is(err, tt.target); got != tt.want {
+			t.Errorf("errors.Is(%v, %v) = %t, want %t", err, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %s, %t, want %s, %t", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	at := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got, ok := parseRetryAfter(at); !ok || got <= 0 || got > time.Minute {
+		t.Errorf("parseRetryAfter(%q) = %s, %t", at, got, ok)
+	}
+}
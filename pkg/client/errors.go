@@ -0,0 +1,124 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// APIError is returned for every non 2xx response. It carries the
+// code, message and fields of the API's Error envelope.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Fields     []FieldError
+	TraceID    string
+}
+
+type FieldError struct {
+	Name    string
+	Message string
+}
+
+// Sentinels for errors.Is. Code sentinels match the code of the Error
+// envelope, status sentinels match any error with that status.
+var (
+	ErrUserNotFound            = &APIError{Code: "user_not_found"}
+	ErrUserAlreadyExists       = &APIError{Code: "user_already_exists"}
+	ErrUserHasBalance          = &APIError{Code: "user_has_balance"}
+	ErrUserNotDeleted          = &APIError{Code: "user_not_deleted"}
+	ErrEmailAlreadyInUse       = &APIError{Code: "email_already_in_use"}
+	ErrVersionMismatch         = &APIError{Code: "version_mismatch"}
+	ErrWalletNotFound          = &APIError{Code: "wallet_not_found"}
+	ErrWalletHasBalance        = &APIError{Code: "wallet_has_balance"}
+	ErrWalletNotDeleted        = &APIError{Code: "wallet_not_deleted"}
+	ErrWalletOwnerDeleted      = &APIError{Code: "wallet_owner_deleted"}
+	ErrWalletStatusStale       = &APIError{Code: "wallet_status_stale"}
+	ErrWalletFrozen            = &APIError{Code: "wallet_frozen"}
+	ErrWalletClosed            = &APIError{Code: "wallet_closed"}
+	ErrWalletDebitBlocked      = &APIError{Code: "wallet_debit_blocked"}
+	ErrWalletCreditBlocked     = &APIError{Code: "wallet_credit_blocked"}
+	ErrInvalidWalletStatus     = &APIError{Code: "invalid_wallet_status"}
+	ErrStatusReasonRequired    = &APIError{Code: "status_reason_required"}
+	ErrInvalidStatusTransition = &APIError{Code: "invalid_status_transition"}
+	ErrUnauthenticated         = &APIError{Code: "unauthenticated"}
+	ErrPermissionDenied        = &APIError{Code: "permission_denied"}
+
+	ErrBadRequest         = &APIError{StatusCode: http.StatusBadRequest}
+	ErrNotFound           = &APIError{StatusCode: http.StatusNotFound}
+	ErrConflict           = &APIError{StatusCode: http.StatusConflict}
+	ErrPreconditionFailed = &APIError{StatusCode: http.StatusPreconditionFailed}
+	ErrRateLimited        = &APIError{StatusCode: http.StatusTooManyRequests}
+	ErrInternal           = &APIError{StatusCode: http.StatusInternalServerError}
+)
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("grey api: %d", e.StatusCode)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.TraceID != "" {
+		msg += " (trace " + e.TraceID + ")"
+	}
+	return msg
+}
+
+// Is matches code sentinels by code and status sentinels by status.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	if t.Code != "" {
+		return t.Code == e.Code
+	}
+	return t.StatusCode != 0 && t.StatusCode == e.StatusCode
+}
+
+type errorEnvelope struct {
+	Error *struct {
+		Code    *string `json:"code"`
+		Message *string `json:"message"`
+		Fields  []struct {
+			Name    *string `json:"name"`
+			Message *string `json:"message"`
+		} `json:"fields"`
+	} `json:"error"`
+	Meta *meta `json:"meta"`
+}
+
+// newAPIError decodes the Error envelope, falling back to the raw
+// body for responses that do not use it.
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode}
+
+	var envelope errorEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.Error == nil {
+		apiErr.Message = string(body)
+		return apiErr
+	}
+
+	apiErr.Code = deref(envelope.Error.Code)
+	apiErr.Message = deref(envelope.Error.Message)
+	for _, f := range envelope.Error.Fields {
+		apiErr.Fields = append(apiErr.Fields, FieldError{
+			Name:    deref(f.Name),
+			Message: deref(f.Message),
+		})
+	}
+	if envelope.Meta != nil {
+		apiErr.TraceID = deref(envelope.Meta.TraceID)
+	}
+	return apiErr
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type UsersService struct {
+	client *Client
+}
+
+type User struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	Version   int64      `json:"version,string"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	// ETag identifies the version read. Pass it to Update
+	// to fail with ErrVersionMismatch on concurrent edits.
+	ETag string `json:"-"`
+}
+
+type userData struct {
+	User User `json:"user"`
+}
+
+type CreateUserRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// UpdateUserRequest is a partial update, nil fields are left unchanged.
+type UpdateUserRequest struct {
+	Name  *string `json:"name,omitempty"`
+	Email *string `json:"email,omitempty"`
+}
+
+// Create returns the id of the new user.
+func (s *UsersService) Create(ctx context.Context, req CreateUserRequest) (string, error) {
+	var data idData
+	_, err := s.client.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/users",
+		body:   req,
+	}, &data)
+	return data.ID, err
+}
+
+func (s *UsersService) Get(ctx context.Context, id string) (*User, error) {
+	var data userData
+	headers, err := s.client.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/v1/users/" + pathEscape(id),
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+	data.User.ETag = headers.Get("ETag")
+	return &data.User, nil
+}
+
+// Update applies req. A non empty etag makes the update conditional.
+func (s *UsersService) Update(ctx context.Context, id string, req UpdateUserRequest, etag string) (*User, error) {
+	headers := http.Header{}
+	if etag != "" {
+		headers.Set("If-Match", etag)
+	}
+
+	var data userData
+	respHeaders, err := s.client.do(ctx, request{
+		method:  http.MethodPatch,
+		path:    "/v1/users/" + pathEscape(id),
+		body:    req,
+		headers: headers,
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+	data.User.ETag = respHeaders.Get("ETag")
+	return &data.User, nil
+}
+
+// Delete soft deletes the user along with its wallets. It fails with
+// ErrUserHasBalance or ErrWalletFrozen while a wallet holds money or
+// is frozen.
+func (s *UsersService) Delete(ctx context.Context, id string) error {
+	_, err := s.client.do(ctx, request{
+		method: http.MethodDelete,
+		path:   "/v1/users/" + pathEscape(id),
+	}, nil)
+	return err
+}
+
+// Restore undoes Delete.
+func (s *UsersService) Restore(ctx context.Context, id string) error {
+	_, err := s.client.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/admin/users/" + pathEscape(id) + "/restore",
+	}, nil)
+	return err
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type WalletsService struct {
+	client *Client
+}
+
+type WalletStatus string
+
+// Wallet statuses, named as in the protobuf contract of the /v1 routes.
+const (
+	WalletActive        WalletStatus = "WALLET_STATUS_ACTIVE"
+	WalletFrozen        WalletStatus = "WALLET_STATUS_FROZEN"
+	WalletDebitBlocked  WalletStatus = "WALLET_STATUS_DEBIT_BLOCKED"
+	WalletCreditBlocked WalletStatus = "WALLET_STATUS_CREDIT_BLOCKED"
+	WalletClosed        WalletStatus = "WALLET_STATUS_CLOSED"
+)
+
+type Wallet struct {
+	ID           string          `json:"id"`
+	UserID       string          `json:"user_id"`
+	Balance      decimal.Decimal `json:"balance"`
+	Status       WalletStatus    `json:"status"`
+	StatusReason string          `json:"status_reason,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+type walletData struct {
+	Wallet Wallet `json:"wallet"`
+}
+
+type CreateWalletRequest struct {
+	UserID         string          `json:"user_id"`
+	InitialBalance decimal.Decimal `json:"initial_balance"`
+}
+
+// Create returns the id of the new wallet.
+func (s *WalletsService) Create(ctx context.Context, req CreateWalletRequest) (string, error) {
+	var data idData
+	_, err := s.client.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/wallets",
+		body:   req,
+	}, &data)
+	return data.ID, err
+}
+
+func (s *WalletsService) Get(ctx context.Context, id string) (*Wallet, error) {
+	var data walletData
+	_, err := s.client.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/v1/wallets/" + pathEscape(id),
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+	return &data.Wallet, nil
+}
+
+// Delete soft deletes a wallet that holds no balance.
+func (s *WalletsService) Delete(ctx context.Context, id string) error {
+	_, err := s.client.do(ctx, request{
+		method: http.MethodDelete,
+		path:   "/v1/wallets/" + pathEscape(id),
+	}, nil)
+	return err
+}
+
+// Restore undoes Delete.
+func (s *WalletsService) Restore(ctx context.Context, id string) error {
+	_, err := s.client.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/admin/wallets/" + pathEscape(id) + "/restore",
+	}, nil)
+	return err
+}
+
+// ChangeStatus moves the wallet to status.
+func (s *WalletsService) ChangeStatus(ctx context.Context, id string, status WalletStatus, reason string) (*Wallet, error) {
+	var data walletData
+	_, err := s.client.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/admin/wallets/" + pathEscape(id) + "/status",
+		body: struct {
+			Status WalletStatus `json:"status"`
+			Reason string       `json:"reason"`
+		}{status, reason},
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+	return &data.Wallet, nil
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// fanout hands every record at or above level to all of its handlers.
+type fanout struct {
+	level    slog.Leveler
+	handlers []slog.Handler
+}
+
+func (f fanout) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= f.level.Level()
+}
+
+func (f fanout) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range f.handlers {
+		// handlers may keep the record, each gets its own copy
+		if err := h.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(f.handlers))
+	for i, h := range f.handlers {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return fanout{level: f.level, handlers: handlers}
+}
+
+func (f fanout) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(f.handlers))
+	for i, h := range f.handlers {
+		handlers[i] = h.WithGroup(name)
+	}
+	return fanout{level: f.level, handlers: handlers}
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+type levelPayload struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler reports the level on GET and changes
+// it on PUT, both with a {"level": "debug"} body.
+func LevelHandler(level *slog.LevelVar, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var payload levelPayload
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			var l slog.Level
+			if err := l.UnmarshalText([]byte(payload.Level)); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if l != level.Level() {
+				logger.InfoContext(r.Context(), "log level changed", "from", level.Level().String(), "to", l.String())
+				level.Set(l)
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(levelPayload{Level: level.Level().String()})
+	})
+}
@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"context"
+	"crypto/tls"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+type OTLPOptions struct {
+	Resource          *resource.Resource
+	CollectorEndpoint string
+	// TLS secures the connection to the collector, nil sends plaintext.
+	TLS     *tls.Config
+	Headers map[string]string
+}
+
+// NewOTLPProvider returns a LoggerProvider exporting logs to the
+// collector in batches. Records written in a span carry its trace
+// and span IDs.
+func NewOTLPProvider(ctx context.Context, opts OTLPOptions) (*sdklog.LoggerProvider, error) {
+	httpOpts := []otlploghttp.Option{
+		otlploghttp.WithEndpoint(opts.CollectorEndpoint),
+		otlploghttp.WithHeaders(opts.Headers),
+	}
+	if opts.TLS != nil {
+		httpOpts = append(httpOpts, otlploghttp.WithTLSClientConfig(opts.TLS))
+	} else {
+		httpOpts = append(httpOpts, otlploghttp.WithInsecure())
+	}
+	exporter, err := otlploghttp.New(ctx, httpOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdklog.NewLoggerProvider(
+		sdklog.WithResource(opts.Resource),
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+	), nil
+}
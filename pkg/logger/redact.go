@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Oloruntobi1/grey/pkg/redact"
+)
+
+// redactHandler applies the policy to messages and attributes before
+// any other handler, span events and exported logs included, sees them.
+type redactHandler struct {
+	h      slog.Handler
+	policy *redact.Policy
+}
+
+func (r redactHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return r.h.Enabled(ctx, l)
+}
+
+func (r redactHandler) Handle(ctx context.Context, rec slog.Record) error {
+	out := slog.NewRecord(rec.Time, rec.Level, r.policy.Text(rec.Message), rec.PC)
+	rec.Attrs(func(a slog.Attr) bool {
+		if a, ok := r.policy.Attr(a); ok {
+			out.AddAttrs(a)
+		}
+		return true
+	})
+	return r.h.Handle(ctx, out)
+}
+
+func (r redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if a, ok := r.policy.Attr(a); ok {
+			redacted = append(redacted, a)
+		}
+	}
+	return redactHandler{h: r.h.WithAttrs(redacted), policy: r.policy}
+}
+
+func (r redactHandler) WithGroup(name string) slog.Handler {
+	return redactHandler{h: r.h.WithGroup(name), policy: r.policy}
+}
@@ -1,31 +1,77 @@
 package logger
 
 import (
-	"context"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
-	"sync"
 
 	"github.com/Oloruntobi1/grey/pkg/otel"
+	"github.com/Oloruntobi1/grey/pkg/redact"
+	"go.opentelemetry.io/contrib/bridges/otelslog"
+	"go.opentelemetry.io/otel/log"
 )
 
-var (
-	onceSlog   sync.Once
-	slogLogger *slog.Logger
-)
+type Options struct {
+	// Level is shared with LevelHandler so
+	// it can be changed while running.
+	Level *slog.LevelVar
+	// Format is json or text.
+	Format    string
+	Output    io.Writer
+	AddSource bool
+
+	// Provider also receives every record when set,
+	// typically to export them over OTLP.
+	Provider log.LoggerProvider
+
+	// Redact is applied to every record before it is written,
+	// added to a span or exported.
+	Redact *redact.Policy
+}
 
-func NewSlog(ctx context.Context) *slog.Logger {
-	onceSlog.Do(func() {
-		opts := slog.HandlerOptions{
-			AddSource: true,
-			Level:     slog.LevelDebug,
+// New returns the application logger. Records are written to the
+// output and, when a span is recording, added to it as events.
+func New(opts Options) *slog.Logger {
+	handlerOpts := slog.HandlerOptions{
+		AddSource: opts.AddSource,
+		Level:     opts.Level,
+	}
+
+	var h slog.Handler
+	if opts.Format == "text" {
+		h = slog.NewTextHandler(opts.Output, &handlerOpts)
+	} else {
+		h = slog.NewJSONHandler(opts.Output, &handlerOpts)
+	}
+
+	if opts.Provider != nil {
+		h = fanout{
+			level:    opts.Level,
+			handlers: []slog.Handler{h, otelslog.NewHandler("grey", otelslog.WithLoggerProvider(opts.Provider))},
 		}
-		jh := slog.NewJSONHandler(os.Stdout, &opts)
+	}
 
-		h := otel.OtelHandler{H: jh}
-		l := slog.New(h).With("app", "grey-wallet-app")
-		slogLogger = l
-	})
+	h = otel.OtelHandler{H: h}
+	if opts.Redact != nil {
+		h = redactHandler{h: h, policy: opts.Redact}
+	}
+
+	return slog.New(h).With("app", "grey-wallet-app")
+}
 
-	return slogLogger
+// Output opens where logs are written: stdout, stderr
+// or the file at the path, which is appended to.
+func Output(name string) (io.Writer, error) {
+	switch name {
+	case "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log output: %w", err)
+	}
+	return f, nil
 }
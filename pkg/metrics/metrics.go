@@ -2,41 +2,76 @@ package metrics
 
 import (
 	"context"
+	"crypto/tls"
+	"net/http"
 	"time"
 
-	"github.com/Oloruntobi1/grey/internal/config"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/prometheus"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
-	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 )
 
-func SetupMetrics(ctx context.Context, serviceName string) (*sdkmetric.MeterProvider, error) {
-	exporter, err := otlpmetrichttp.New(
-		ctx,
-		otlpmetrichttp.WithEndpoint(config.GetOtelCollectorConfig()),
-		otlpmetrichttp.WithInsecure(),
-	)
-	if err != nil {
-		return nil, err
-	}
+type Options struct {
+	Resource *resource.Resource
+
+	// OTLP pushes metrics to the collector at
+	// CollectorEndpoint every Interval.
+	OTLP              bool
+	CollectorEndpoint string
+	Interval          time.Duration
+	// TLS secures the connection to the collector, nil sends plaintext.
+	TLS     *tls.Config
+	Headers map[string]string
 
+	// Prometheus makes SetupMetrics return a handler
+	// serving the metrics in the Prometheus format.
+	Prometheus bool
+}
+
+// SetupMetrics installs the global MeterProvider. The returned handler
+// is nil unless the Prometheus exporter is enabled.
+func SetupMetrics(ctx context.Context, opts Options) (*sdkmetric.MeterProvider, http.Handler, error) {
 	// labels/tags/resources that are common to all metrics.
-	resource := resource.NewWithAttributes(
-		semconv.SchemaURL,
-		semconv.ServiceNameKey.String(serviceName),
-	)
-
-	mp := sdkmetric.NewMeterProvider(
-		sdkmetric.WithResource(resource),
-		sdkmetric.WithReader(
-			// collects and exports metric data every 30 seconds.
-			sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(30*time.Second)),
-		),
-	)
+	providerOpts := []sdkmetric.Option{sdkmetric.WithResource(opts.Resource)}
+
+	if opts.OTLP {
+		httpOpts := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithEndpoint(opts.CollectorEndpoint),
+			otlpmetrichttp.WithHeaders(opts.Headers),
+		}
+		if opts.TLS != nil {
+			httpOpts = append(httpOpts, otlpmetrichttp.WithTLSClientConfig(opts.TLS))
+		} else {
+			httpOpts = append(httpOpts, otlpmetrichttp.WithInsecure())
+		}
+		exporter, err := otlpmetrichttp.New(ctx, httpOpts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		providerOpts = append(providerOpts, sdkmetric.WithReader(
+			// collects and exports metric data every interval.
+			sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(opts.Interval)),
+		))
+	}
+
+	var handler http.Handler
+	if opts.Prometheus {
+		// the exporter is a reader collecting on every scrape,
+		// registered with the default Prometheus registry
+		exporter, err := prometheus.New()
+		if err != nil {
+			return nil, nil, err
+		}
+		providerOpts = append(providerOpts, sdkmetric.WithReader(exporter))
+		handler = promhttp.Handler()
+	}
+
+	mp := sdkmetric.NewMeterProvider(providerOpts...)
 
 	otel.SetMeterProvider(mp)
 
-	return mp, nil
+	return mp, handler, nil
 }
@@ -18,8 +18,8 @@ import (
 // (b) Logs(as events) to the active span.
 type OtelHandler struct{ H slog.Handler }
 
-func (s OtelHandler) Enabled(_ context.Context, _ slog.Level) bool {
-	return true /* support all logging levels*/
+func (s OtelHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return s.H.Enabled(ctx, l)
 }
 
 func (s OtelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
@@ -0,0 +1,164 @@
+// Package redact keeps personal data out of logs, spans and audit
+// records. A Policy names the fields whose values are always sensitive
+// and the patterns of values that are sensitive wherever they appear,
+// and whether they are masked, hashed or dropped.
+//
+// A nil *Policy redacts nothing.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// Mode is how a sensitive value is replaced.
+type Mode string
+
+const (
+	// Mask replaces the value with Masked.
+	Mask Mode = "mask"
+	// Hash replaces the value with its HMAC-SHA256, so equal values
+	// can still be matched across logs, spans and audit records.
+	Hash Mode = "hash"
+	// Drop removes sensitive fields altogether. Patterns found inside
+	// a longer value are masked as the rest of the value is kept.
+	Drop Mode = "drop"
+)
+
+// Modes are the valid modes.
+var Modes = []Mode{Mask, Hash, Drop}
+
+// Masked replaces masked values.
+const Masked = "***"
+
+// Patterns are the value patterns a Policy can look for.
+var Patterns = map[string]*regexp.Regexp{
+	"email":  regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+	"card":   regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+	"bearer": regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/\-]+=*`),
+}
+
+// Options configure a Policy.
+type Options struct {
+	Mode Mode
+	// Fields are the names of the attributes, log keys and
+	// columns that are redacted whatever their value.
+	Fields []string
+	// Patterns are names from Patterns, values matching
+	// them are redacted in every field.
+	Patterns []string
+	// HashKey keys the hashes so they cannot be reversed
+	// by hashing guesses. It is required to Hash.
+	HashKey string
+}
+
+// Policy redacts values by field name and pattern. It is safe
+// for concurrent use.
+type Policy struct {
+	mode     Mode
+	fields   map[string]bool
+	patterns []*regexp.Regexp
+	hashKey  string
+}
+
+// New returns the policy for opts. It fails on an unknown mode or
+// pattern, and when hashing without a key.
+func New(opts Options) (*Policy, error) {
+	switch opts.Mode {
+	case Mask, Drop:
+	case Hash:
+		if opts.HashKey == "" {
+			return nil, fmt.Errorf("redact: %s mode needs a hash key", Hash)
+		}
+	default:
+		return nil, fmt.Errorf("redact: unknown mode %q", opts.Mode)
+	}
+
+	p := &Policy{
+		mode:    opts.Mode,
+		fields:  make(map[string]bool, len(opts.Fields)),
+		hashKey: opts.HashKey,
+	}
+	for _, field := range opts.Fields {
+		p.fields[normalize(field)] = true
+	}
+	for _, name := range opts.Patterns {
+		re, ok := Patterns[name]
+		if !ok {
+			return nil, fmt.Errorf("redact: unknown pattern %q", name)
+		}
+		p.patterns = append(p.patterns, re)
+	}
+	return p, nil
+}
+
+var defaultPolicy atomic.Pointer[Policy]
+
+// SetDefault makes p the policy used by the package level helpers.
+func SetDefault(p *Policy) {
+	defaultPolicy.Store(p)
+}
+
+// Default returns the policy set with SetDefault, nil until then.
+func Default() *Policy {
+	return defaultPolicy.Load()
+}
+
+// Field reports whether values of the named field are always redacted.
+// Names are matched ignoring case, dashes and underscores alike.
+func (p *Policy) Field(name string) bool {
+	if p == nil {
+		return false
+	}
+	return p.fields[normalize(name)]
+}
+
+// Value redacts the value of the named field. The second result is
+// false when the field is sensitive and dropped.
+func (p *Policy) Value(name, value string) (string, bool) {
+	if p == nil {
+		return value, true
+	}
+	if p.Field(name) {
+		if p.mode == Drop {
+			return "", false
+		}
+		return p.replace(value), true
+	}
+	return p.Text(value), true
+}
+
+// Text redacts the parts of free text, such as log messages
+// and errors, matching the policy patterns.
+func (p *Policy) Text(s string) string {
+	if p == nil {
+		return s
+	}
+	for _, re := range p.patterns {
+		s = re.ReplaceAllStringFunc(s, p.replace)
+	}
+	return s
+}
+
+func (p *Policy) replace(value string) string {
+	if p.mode == Hash {
+		return hash(p.hashKey, value)
+	}
+	return Masked
+}
+
+// hash must stay in line with the redact_hash function of AuditSetup.
+func hash(key, value string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(value))
+	return "hmac:" + hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+func normalize(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_")
+}
@@ -0,0 +1,120 @@
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{name: "mask", opts: Options{Mode: Mask, Fields: []string{"email"}, Patterns: []string{"email", "card", "bearer"}}},
+		{name: "drop", opts: Options{Mode: Drop}},
+		{name: "hash", opts: Options{Mode: Hash, HashKey: "key"}},
+		{name: "hash without key", opts: Options{Mode: Hash}, wantErr: true},
+		{name: "unknown mode", opts: Options{Mode: "blur"}, wantErr: true},
+		{name: "no mode", opts: Options{}, wantErr: true},
+		{name: "unknown pattern", opts: Options{Mode: Mask, Patterns: []string{"phone"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if _, err := New(tt.opts); (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, want error %t", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("ada@example.com"))
+	want := "hmac:" + hex.EncodeToString(mac.Sum(nil))[:16]
+
+	if got := hash("secret", "ada@example.com"); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if hash("other", "ada@example.com") == want {
+		t.Error("hashes with different keys are equal")
+	}
+	if hash("secret", "bob@example.com") == want {
+		t.Error("hashes of different values are equal")
+	}
+}
+
+func TestValue(t *testing.T) {
+	hashed := hash("secret", "ada@example.com")
+
+	tests := []struct {
+		name   string
+		mode   Mode
+		field  string
+		value  string
+		want   string
+		wantOK bool
+	}{
+		{name: "masked field", mode: Mask, field: "email", value: "ada@example.com", want: Masked, wantOK: true},
+		{name: "field ignoring case and dashes", mode: Mask, field: "Phone-Number", value: "0801", want: Masked, wantOK: true},
+		{name: "hashed field", mode: Hash, field: "email", value: "ada@example.com", want: hashed, wantOK: true},
+		{name: "dropped field", mode: Drop, field: "email", value: "ada@example.com", wantOK: false},
+		{name: "pattern in other field", mode: Mask, field: "note", value: "from ada@example.com", want: "from " + Masked, wantOK: true},
+		{name: "pattern hashed in other field", mode: Hash, field: "note", value: "from ada@example.com", want: "from " + hashed, wantOK: true},
+		{name: "pattern masked when dropping", mode: Drop, field: "note", value: "from ada@example.com", want: "from " + Masked, wantOK: true},
+		{name: "plain value", mode: Mask, field: "name", value: "Ada", want: "Ada", wantOK: true},
+	}
+	for _, tt := range tests {
+		p, err := New(Options{
+			Mode:     tt.mode,
+			Fields:   []string{"email", "phone_number"},
+			Patterns: []string{"email"},
+			HashKey:  "secret",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := p.Value(tt.field, tt.value)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: got %q, %t, want %q, %t", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	p, err := New(Options{Mode: Mask, Patterns: []string{"email", "card", "bearer"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "no personal data", want: "no personal data"},
+		{in: "user ada@example.com not found", want: "user *** not found"},
+		{in: "a@b.io and c@d.io", want: "*** and ***"},
+		{in: "card 4111 1111 1111 1111 declined", want: "card *** declined"},
+		{in: "card 4111-1111-1111-1111", want: "card ***"},
+		{in: "order 12345 failed", want: "order 12345 failed"},
+		{in: "Authorization: Bearer abc.def-ghi==", want: "Authorization: ***"},
+	}
+	for _, tt := range tests {
+		if got := p.Text(tt.in); got != tt.want {
+			t.Errorf("Text(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNilPolicy(t *testing.T) {
+	var p *Policy
+	if p.Field("email") {
+		t.Error("nil policy has fields")
+	}
+	if got, ok := p.Value("email", "ada@example.com"); got != "ada@example.com" || !ok {
+		t.Errorf("Value = %q, %t", got, ok)
+	}
+	if got := p.Text("ada@example.com"); got != "ada@example.com" {
+		t.Errorf("Text = %q", got)
+	}
+}
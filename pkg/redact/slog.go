@@ -0,0 +1,43 @@
+package redact
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// Attr redacts a log attribute, groups included. The second
+// result is false when the attribute is dropped.
+func (p *Policy) Attr(a slog.Attr) (slog.Attr, bool) {
+	if p == nil {
+		return a, true
+	}
+
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, 0, len(group))
+		for _, ga := range group {
+			if ga, ok := p.Attr(ga); ok {
+				attrs = append(attrs, ga)
+			}
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}, true
+	case slog.KindString:
+		v, ok := p.Value(a.Key, a.Value.String())
+		return slog.String(a.Key, v), ok
+	case slog.KindAny:
+		// errors and stringers are logged as their text
+		switch a.Value.Any().(type) {
+		case error, fmt.Stringer:
+			v, ok := p.Value(a.Key, a.Value.String())
+			return slog.String(a.Key, v), ok
+		}
+	}
+
+	if p.Field(a.Key) {
+		v, ok := p.Value(a.Key, a.Value.String())
+		return slog.String(a.Key, v), ok
+	}
+	return a, true
+}
@@ -0,0 +1,114 @@
+package redact
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+// flatten renders the leaves of a, keyed by their dotted path.
+func flatten(prefix string, a slog.Attr, out map[string]string) {
+	key := a.Key
+	if prefix != "" {
+		key = prefix + "." + key
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			flatten(key, ga, out)
+		}
+		return
+	}
+	out[key] = a.Value.String()
+}
+
+func TestAttr(t *testing.T) {
+	attr := slog.Group("request",
+		slog.String("email", "ada@example.com"),
+		slog.String("note", "from ada@example.com"),
+		slog.Int("status", 200),
+		slog.Group("user",
+			slog.String("id", "u1"),
+			slog.Int("phone", 801),
+			slog.Any("err", errors.New("no user ada@example.com")),
+		),
+	)
+	hashed := hash("secret", "ada@example.com")
+
+	tests := []struct {
+		mode Mode
+		want map[string]string
+	}{
+		{
+			mode: Mask,
+			want: map[string]string{
+				"request.email":      Masked,
+				"request.note":       "from " + Masked,
+				"request.status":     "200",
+				"request.user.id":    "u1",
+				"request.user.phone": Masked,
+				"request.user.err":   "no user " + Masked,
+			},
+		},
+		{
+			mode: Hash,
+			want: map[string]string{
+				"request.email":      hashed,
+				"request.note":       "from " + hashed,
+				"request.status":     "200",
+				"request.user.id":    "u1",
+				"request.user.phone": hash("secret", "801"),
+				"request.user.err":   "no user " + hashed,
+			},
+		},
+		{
+			mode: Drop,
+			want: map[string]string{
+				"request.note":     "from " + Masked,
+				"request.status":   "200",
+				"request.user.id":  "u1",
+				"request.user.err": "no user " + Masked,
+			},
+		},
+	}
+	for _, tt := range tests {
+		p, err := New(Options{
+			Mode:     tt.mode,
+			Fields:   []string{"email", "phone"},
+			Patterns: []string{"email"},
+			HashKey:  "secret",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		redacted, ok := p.Attr(attr)
+		if !ok {
+			t.Fatalf("%s: group dropped", tt.mode)
+		}
+		got := map[string]string{}
+		flatten("", redacted, got)
+
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.mode, got, tt.want)
+			continue
+		}
+		for key, want := range tt.want {
+			if got[key] != want {
+				t.Errorf("%s: %s = %q, want %q", tt.mode, key, got[key], want)
+			}
+		}
+	}
+}
+
+func TestAttrDropsField(t *testing.T) {
+	p, err := New(Options{Mode: Drop, Fields: []string{"email"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.Attr(slog.String("Email", "ada@example.com")); ok {
+		t.Error("email was kept")
+	}
+	if a, ok := p.Attr(slog.String("name", "Ada")); !ok || a.Value.String() != "Ada" {
+		t.Errorf("name = %v, %t", a, ok)
+	}
+}
@@ -0,0 +1,81 @@
+package redact
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attributes redacts span attributes. String values are redacted by
+// their key and content, other values only when their key is a field.
+func (p *Policy) Attributes(attrs ...attribute.KeyValue) []attribute.KeyValue {
+	if p == nil {
+		return attrs
+	}
+
+	out := make([]attribute.KeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		key := string(kv.Key)
+		switch {
+		case kv.Value.Type() == attribute.STRING:
+			if v, ok := p.Value(key, kv.Value.AsString()); ok {
+				out = append(out, kv.Key.String(v))
+			}
+		case kv.Value.Type() == attribute.STRINGSLICE:
+			if p.Field(key) && p.mode == Drop {
+				continue
+			}
+			values := kv.Value.AsStringSlice()
+			redacted := make([]string, len(values))
+			for i, v := range values {
+				redacted[i], _ = p.Value(key, v)
+			}
+			out = append(out, kv.Key.StringSlice(redacted))
+		case p.Field(key):
+			if v, ok := p.Value(key, kv.Value.Emit()); ok {
+				out = append(out, kv.Key.String(v))
+			}
+		default:
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
+// RecordError marks the span as failed and records err as an
+// exception event like trace.Span.RecordError, with the message
+// and attributes redacted.
+func (p *Policy) RecordError(span trace.Span, err error, attrs ...attribute.KeyValue) {
+	if err == nil {
+		return
+	}
+	msg := p.Text(err.Error())
+	span.SetStatus(codes.Error, msg)
+	span.AddEvent(semconv.ExceptionEventName, trace.WithAttributes(append(
+		[]attribute.KeyValue{semconv.ExceptionType(typeStr(err)), semconv.ExceptionMessage(msg)},
+		p.Attributes(attrs...)...,
+	)...))
+}
+
+// Attributes redacts attrs with the default policy.
+func Attributes(attrs ...attribute.KeyValue) []attribute.KeyValue {
+	return Default().Attributes(attrs...)
+}
+
+// RecordError records err on the span with the default policy.
+func RecordError(span trace.Span, err error, attrs ...attribute.KeyValue) {
+	Default().RecordError(span, err, attrs...)
+}
+
+// typeStr names the type of err the way the SDK does.
+func typeStr(err error) string {
+	t := reflect.TypeOf(err)
+	if t.PkgPath() == "" && t.Name() == "" {
+		return t.String()
+	}
+	return fmt.Sprintf("%s.%s", t.PkgPath(), t.Name())
+}
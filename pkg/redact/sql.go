@@ -0,0 +1,83 @@
+package redact
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Statement is a SQL statement and the arguments of its placeholders.
+type Statement struct {
+	SQL  string
+	Args []any
+}
+
+// AuditSetup returns the statements to run before creating triggers
+// with AuditJSON. To hash, the key is stored in the redact_settings
+// table, which only its owner can read, and the redact_hash function
+// reads it from there so it never appears in any function source.
+// Execute on redact_hash is revoked from PUBLIC, other roles writing
+// audited tables have to be granted it. Other modes drop both.
+func (p *Policy) AuditSetup() []Statement {
+	if p == nil || p.mode != Hash {
+		return []Statement{
+			{SQL: "DROP FUNCTION IF EXISTS redact_hash(TEXT)"},
+			{SQL: "DROP TABLE IF EXISTS redact_settings"},
+		}
+	}
+
+	return []Statement{
+		{SQL: "CREATE EXTENSION IF NOT EXISTS pgcrypto"},
+		{SQL: "CREATE TABLE IF NOT EXISTS redact_settings (name TEXT PRIMARY KEY, value TEXT NOT NULL)"},
+		{SQL: "REVOKE ALL ON redact_settings FROM PUBLIC"},
+		{
+			SQL: `INSERT INTO redact_settings (name, value) VALUES ('hash_key', $1)
+				ON CONFLICT (name) DO UPDATE SET value = EXCLUDED.value`,
+			Args: []any{p.hashKey},
+		},
+		// the same HMAC-SHA256 as hash, a NULL stays NULL
+		{SQL: `CREATE OR REPLACE FUNCTION redact_hash(input TEXT) RETURNS TEXT
+				LANGUAGE sql STABLE SECURITY DEFINER
+				SET search_path = pg_catalog, public
+				AS $$
+					SELECT 'hmac:' || left(encode(hmac(convert_to(input, 'UTF8'), convert_to(s.value, 'UTF8'), 'sha256'), 'hex'), 16)
+					FROM redact_settings s
+					WHERE s.name = 'hash_key'
+				$$`},
+		{SQL: "REVOKE ALL ON FUNCTION redact_hash(TEXT) FROM PUBLIC"},
+	}
+}
+
+// AuditJSON wraps a SQL expression building a JSONB object from a row,
+// such as to_jsonb(NEW), so the fields of the policy are redacted in
+// the database. Patterns are not applied, only whole columns.
+func (p *Policy) AuditJSON(expr string) string {
+	if p == nil || len(p.fields) == 0 {
+		return expr
+	}
+
+	fields := make([]string, 0, len(p.fields))
+	for field := range p.fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	if p.mode == Drop {
+		return fmt.Sprintf("(%s) - %s::text[]", expr, quote("{"+strings.Join(fields, ",")+"}"))
+	}
+
+	out := expr
+	for _, field := range fields {
+		replacement := quote(`"`+Masked+`"`) + "::jsonb"
+		if p.mode == Hash {
+			replacement = fmt.Sprintf("COALESCE(to_jsonb(redact_hash((%s) ->> %s)), 'null'::jsonb)", expr, quote(field))
+		}
+		// create_missing is false, tables without the column are left alone
+		out = fmt.Sprintf("jsonb_set(%s, %s, %s, false)", out, quote("{"+field+"}"), replacement)
+	}
+	return out
+}
+
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
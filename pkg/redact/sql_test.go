@@ -0,0 +1,96 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAuditJSON(t *testing.T) {
+	const expr = "to_jsonb(NEW)"
+
+	tests := []struct {
+		name   string
+		mode   Mode
+		fields []string
+		want   string
+	}{
+		{
+			name:   "no fields",
+			mode:   Mask,
+			fields: nil,
+			want:   expr,
+		},
+		{
+			name:   "drop removes the columns",
+			mode:   Drop,
+			fields: []string{"phone", "Email"},
+			want:   `(to_jsonb(NEW)) - '{email,phone}'::text[]`,
+		},
+		{
+			name:   "mask replaces the columns",
+			mode:   Mask,
+			fields: []string{"phone", "email"},
+			want: `jsonb_set(jsonb_set(to_jsonb(NEW), '{email}', '"***"'::jsonb, false), ` +
+				`'{phone}', '"***"'::jsonb, false)`,
+		},
+		{
+			name:   "hash replaces the columns with their hash",
+			mode:   Hash,
+			fields: []string{"email"},
+			want: `jsonb_set(to_jsonb(NEW), '{email}', ` +
+				`COALESCE(to_jsonb(redact_hash((to_jsonb(NEW)) ->> 'email')), 'null'::jsonb), false)`,
+		},
+		{
+			name:   "quotes are escaped",
+			mode:   Drop,
+			fields: []string{"o'brien"},
+			want:   `(to_jsonb(NEW)) - '{o''brien}'::text[]`,
+		},
+	}
+	for _, tt := range tests {
+		p, err := New(Options{Mode: tt.mode, Fields: tt.fields, HashKey: "secret"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.AuditJSON(expr); got != tt.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tt.name, got, tt.want)
+		}
+	}
+
+	var p *Policy
+	if got := p.AuditJSON(expr); got != expr {
+		t.Errorf("nil policy: got %s", got)
+	}
+}
+
+func TestAuditSetup(t *testing.T) {
+	p, err := New(Options{Mode: Hash, HashKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keyStored bool
+	for _, stmt := range p.AuditSetup() {
+		for _, arg := range stmt.Args {
+			if arg == "secret" {
+				keyStored = true
+			}
+		}
+		// the key is only ever passed as an argument
+		if strings.Contains(stmt.SQL, "secret") {
+			t.Errorf("key in statement %q", stmt.SQL)
+		}
+	}
+	if !keyStored {
+		t.Error("key is not stored")
+	}
+
+	p, err = New(Options{Mode: Mask})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range p.AuditSetup() {
+		if !strings.HasPrefix(stmt.SQL, "DROP ") {
+			t.Errorf("mask mode runs %q", stmt.SQL)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Package telemetry holds what the tracer and meter providers share:
+// the resource describing this process and the exporter TLS settings.
+package telemetry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+// NewResource describes the service along with the host, container and
+// process it runs in. OTEL_RESOURCE_ATTRIBUTES adds or overrides
+// attributes. An empty version falls back to the VCS revision the
+// binary was built from.
+func NewResource(ctx context.Context, serviceName, serviceVersion, environment string) (*resource.Resource, error) {
+	if serviceVersion == "" {
+		serviceVersion = buildRevision()
+	}
+
+	opts := []resource.Option{
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithHost(),
+		resource.WithContainer(),
+		resource.WithOS(),
+		resource.WithProcessRuntimeName(),
+		resource.WithProcessRuntimeVersion(),
+		resource.WithProcessPID(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+	}
+	if serviceVersion != "" {
+		opts = append(opts, resource.WithAttributes(semconv.ServiceVersion(serviceVersion)))
+	}
+	if environment != "" {
+		opts = append(opts, resource.WithAttributes(semconv.DeploymentEnvironment(environment)))
+	}
+	// last so the environment wins
+	opts = append(opts, resource.WithFromEnv())
+
+	res, err := resource.New(ctx, opts...)
+	// detectors that fail, such as the container one outside
+	// of a container, still leave a usable resource
+	if errors.Is(err, resource.ErrPartialResource) {
+		return res, nil
+	}
+	return res, err
+}
+
+func buildRevision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return ""
+}
+
+// ExporterTLS returns the TLS config for connecting to the collector,
+// trusting the CA bundle in caFile when it is set and the system
+// roots otherwise.
+func ExporterTLS(caFile string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile == "" {
+		return cfg, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read collector CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	cfg.RootCAs = pool
+	return cfg, nil
+}
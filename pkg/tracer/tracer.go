@@ -2,37 +2,60 @@ package tracer
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
 
-	"github.com/Oloruntobi1/grey/internal/config"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"google.golang.org/grpc/credentials"
 )
 
-func StartTracer(ctx context.Context, serviceName string) (*sdktrace.TracerProvider, error) {
-	exporter, err := otlptracehttp.New(
-		ctx,
-		otlptracehttp.WithEndpoint(config.GetOtelCollectorConfig()),
-		otlptracehttp.WithInsecure(),
-	)
+const (
+	ExporterOTLPHTTP = "otlphttp"
+	ExporterOTLPGRPC = "otlpgrpc"
+	ExporterStdout   = "stdout"
+	ExporterNone     = "none"
+)
+
+type Options struct {
+	Resource *resource.Resource
+
+	// Exporter is one of the Exporter constants. With ExporterNone
+	// spans are still created, so trace IDs reach logs and responses,
+	// but they are not sent anywhere.
+	Exporter string
+	// Endpoint is the host:port of the OTLP collector.
+	Endpoint string
+	// TLS secures the connection to the collector, nil sends plaintext.
+	TLS     *tls.Config
+	Headers map[string]string
+
+	// SampleRatio is the share of new traces that are sampled.
+	// Spans with a parent follow the parent's decision.
+	SampleRatio float64
+}
+
+func StartTracer(ctx context.Context, opts Options) (*sdktrace.TracerProvider, error) {
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+		sdktrace.WithResource(opts.Resource),
+	}
+
+	exporter, err := newExporter(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
+	if exporter != nil {
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	}
 
-	resource := resource.NewWithAttributes(
-		semconv.SchemaURL,
-		semconv.ServiceNameKey.String(serviceName),
-		// attribute.String("some-attribute", "some-value"),
-	)
-
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
-		sdktrace.WithBatcher(exporter),
-		sdktrace.WithResource(resource),
-	)
+	tp := sdktrace.NewTracerProvider(providerOpts...)
 	// set global tracer
 	otel.SetTracerProvider(tp)
 
@@ -40,3 +63,36 @@ func StartTracer(ctx context.Context, serviceName string) (*sdktrace.TracerProvi
 
 	return tp, nil
 }
+
+func newExporter(ctx context.Context, opts Options) (sdktrace.SpanExporter, error) {
+	switch opts.Exporter {
+	case ExporterOTLPHTTP:
+		httpOpts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(opts.Endpoint),
+			otlptracehttp.WithHeaders(opts.Headers),
+		}
+		if opts.TLS != nil {
+			httpOpts = append(httpOpts, otlptracehttp.WithTLSClientConfig(opts.TLS))
+		} else {
+			httpOpts = append(httpOpts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, httpOpts...)
+	case ExporterOTLPGRPC:
+		grpcOpts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(opts.Endpoint),
+			otlptracegrpc.WithHeaders(opts.Headers),
+		}
+		if opts.TLS != nil {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(opts.TLS)))
+		} else {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, grpcOpts...)
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", opts.Exporter)
+	}
+}